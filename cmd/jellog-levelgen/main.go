@@ -0,0 +1,239 @@
+// Command jellog-levelgen generates Go code that makes custom jellog levels as
+// convenient to use as the built-in ones.
+//
+// Given a list of level names and severities, it generates a jellog.Level
+// variable for each level that is registered with jellog.RegisterLevel on init,
+// a wrapper type around jellog.Logger with a method for logging at each level
+// (such as Notice and Noticef for a level named NOTICE), and package-level
+// functions that log at each level using the default jellog logger.
+//
+// Usage:
+//
+//	jellog-levelgen [flags] NAME=SEVERITY[,ALIAS...] ...
+//
+// Each level is given as its name, an equals sign, and its severity, optionally
+// followed by a comma-separated list of aliases to register it under. For
+// example, "NOTICE=50,note" creates a level named NOTICE with severity 50 that
+// can also be parsed from "note".
+//
+// It is intended to be invoked by go generate, as in:
+//
+//	//go:generate go run github.com/dekarrin/jellog/cmd/jellog-levelgen NOTICE=50 AUDIT=250
+//
+// The flags are:
+//
+//	-o file
+//		Write the generated code to file. Defaults to "jellog_levels.go".
+//	-package name
+//		Use name as the package of the generated code. Defaults to the
+//		package being generated for when run by go generate.
+//	-type name
+//		Use name as the name of the generated Logger wrapper type. Defaults
+//		to "Logger".
+//	-funcs
+//		Whether to generate package-level functions that use the default
+//		jellog logger. Defaults to true.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"go/token"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+type level struct {
+	Name     string
+	Severity int
+	Aliases  []string
+	Var      string
+	Method   string
+}
+
+type genData struct {
+	Package string
+	Type    string
+	Funcs   bool
+	Levels  []level
+}
+
+func main() {
+	output := flag.String("o", "jellog_levels.go", "The file to write generated code to.")
+	pkg := flag.String("package", os.Getenv("GOPACKAGE"), "The package of the generated code.")
+	typeName := flag.String("type", "Logger", "The name of the generated Logger wrapper type.")
+	funcs := flag.Bool("funcs", true, "Whether to generate package-level functions using the default logger.")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: jellog-levelgen [flags] NAME=SEVERITY[,ALIAS...] ...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if err := run(*output, *pkg, *typeName, *funcs, flag.Args()); err != nil {
+		fmt.Fprintf(os.Stderr, "jellog-levelgen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(output, pkg, typeName string, funcs bool, args []string) error {
+	if pkg == "" {
+		return fmt.Errorf("no package given and GOPACKAGE is not set; use -package")
+	}
+	if !token.IsIdentifier(typeName) {
+		return fmt.Errorf("type name %q is not a valid identifier", typeName)
+	}
+	if len(args) < 1 {
+		return fmt.Errorf("no levels given")
+	}
+
+	data := genData{Package: pkg, Type: typeName, Funcs: funcs}
+	seen := map[string]bool{}
+	for _, arg := range args {
+		lv, err := parseLevelArg(arg)
+		if err != nil {
+			return err
+		}
+		if seen[lv.Var] {
+			return fmt.Errorf("level %q is given more than once", lv.Name)
+		}
+		seen[lv.Var] = true
+		data.Levels = append(data.Levels, lv)
+	}
+
+	var buf bytes.Buffer
+	if err := genTemplate.Execute(&buf, data); err != nil {
+		return fmt.Errorf("generate code: %w", err)
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("format generated code: %w", err)
+	}
+
+	if err := os.WriteFile(output, src, 0644); err != nil {
+		return fmt.Errorf("write output: %w", err)
+	}
+	return nil
+}
+
+// parseLevelArg parses a level given as NAME=SEVERITY[,ALIAS...].
+func parseLevelArg(arg string) (level, error) {
+	name, rest, ok := strings.Cut(arg, "=")
+	if !ok {
+		return level{}, fmt.Errorf("level %q is not in NAME=SEVERITY form", arg)
+	}
+	name = strings.TrimSpace(name)
+
+	parts := strings.Split(rest, ",")
+	sev, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return level{}, fmt.Errorf("level %q: severity %q is not an integer", name, parts[0])
+	}
+
+	ident := identFromName(name)
+	if ident == "" {
+		return level{}, fmt.Errorf("level %q: name must contain at least one letter", name)
+	}
+
+	lv := level{
+		Name:     name,
+		Severity: sev,
+		Var:      "Lv" + ident,
+		Method:   ident,
+	}
+	for _, a := range parts[1:] {
+		if a = strings.TrimSpace(a); a != "" {
+			lv.Aliases = append(lv.Aliases, a)
+		}
+	}
+	return lv, nil
+}
+
+// identFromName converts a level name such as "SECURITY_AUDIT" into the
+// CamelCase identifier "SecurityAudit".
+func identFromName(name string) string {
+	var sb strings.Builder
+	upperNext := true
+	for _, ch := range name {
+		if !unicode.IsLetter(ch) && !unicode.IsDigit(ch) {
+			upperNext = true
+			continue
+		}
+		if sb.Len() == 0 && unicode.IsDigit(ch) {
+			continue
+		}
+		if upperNext {
+			sb.WriteRune(unicode.ToUpper(ch))
+			upperNext = false
+		} else {
+			sb.WriteRune(unicode.ToLower(ch))
+		}
+	}
+	return sb.String()
+}
+
+var genTemplate = template.Must(template.New("levels").Parse(`// Code generated by jellog-levelgen; DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"fmt"
+
+	"github.com/dekarrin/jellog"
+)
+
+var (
+{{- range .Levels}}
+	// {{.Var}} is the custom level {{.Name}} with severity {{.Severity}}.
+	{{.Var}} = jellog.Level{Name: {{printf "%q" .Name}}, Severity: {{.Severity}}}
+{{end -}}
+)
+
+func init() {
+{{- range .Levels}}
+	if err := jellog.RegisterLevel({{.Var}}{{range .Aliases}}, {{printf "%q" .}}{{end}}); err != nil {
+		panic(err)
+	}
+{{- end}}
+}
+
+// {{.Type}} is a jellog.Logger with additional methods for logging at custom
+// levels.
+type {{.Type}}[E any] struct {
+	jellog.Logger[E]
+}
+
+// Wrap{{.Type}} returns a {{.Type}} that logs using lg.
+func Wrap{{.Type}}[E any](lg jellog.Logger[E]) {{.Type}}[E] {
+	return {{.Type}}[E]{Logger: lg}
+}
+{{range .Levels}}
+// {{.Method}} logs a message at severity level {{.Name}}. If msg is of type E, it
+// is used directly; otherwise it is converted using the Logger's Converter.
+func (lg {{$.Type}}[E]) {{.Method}}(msg E) {
+	lg.HandleError(lg.Output(2, lg.CreateEvent({{.Var}}, msg)))
+}
+
+// {{.Method}}f logs a formatted message at severity level {{.Name}}.
+func (lg {{$.Type}}[E]) {{.Method}}f(msg string, a ...interface{}) {
+	lg.HandleError(lg.Output(2, lg.CreateEvent({{.Var}}, fmt.Sprintf(msg, a...))))
+}
+{{if $.Funcs}}
+// {{.Method}} logs a message with severity level {{.Name}} using the default
+// logger.
+func {{.Method}}(msg string) {
+	lg := jellog.Default()
+	lg.HandleError(lg.Output(2, lg.CreateEvent({{.Var}}, msg)))
+}
+
+// {{.Method}}f logs a formatted message with severity level {{.Name}} using the
+// default logger.
+func {{.Method}}f(msg string, a ...interface{}) {
+	lg := jellog.Default()
+	lg.HandleError(lg.Output(2, lg.CreateEvent({{.Var}}, fmt.Sprintf(msg, a...))))
+}
+{{end}}{{end}}`))
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func Test_run_golden(t *testing.T) {
+	testCases := []struct {
+		name     string
+		typeName string
+		funcs    bool
+		args     []string
+		golden   string
+	}{
+		{
+			name:     "with funcs",
+			typeName: "Logger",
+			funcs:    true,
+			args:     []string{"NOTICE=50,note", "SECURITY_AUDIT=250,audit,sec"},
+			golden:   "levels.golden",
+		},
+		{
+			name:     "without funcs",
+			typeName: "AppLogger",
+			funcs:    false,
+			args:     []string{"notice=50"},
+			golden:   "levels_nofuncs.golden",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			output := filepath.Join(t.TempDir(), "jellog_levels.go")
+
+			if err := run(output, "levels", tc.typeName, tc.funcs, tc.args); err != nil {
+				t.Fatalf("run: %v", err)
+			}
+
+			actual, err := os.ReadFile(output)
+			if err != nil {
+				t.Fatalf("read output: %v", err)
+			}
+			golden := filepath.Join("testdata", tc.golden)
+			if *update {
+				if err := os.WriteFile(golden, actual, 0644); err != nil {
+					t.Fatalf("update golden file: %v", err)
+				}
+			}
+			expect, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("read golden file: %v", err)
+			}
+			if !bytes.Equal(actual, expect) {
+				t.Errorf("output does not match %s; run with -update if the change is intended. got:\n%s", golden, actual)
+			}
+		})
+	}
+}
+
+func Test_run_errors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		pkg      string
+		typeName string
+		args     []string
+	}{
+		{name: "no package", pkg: "", typeName: "Logger", args: []string{"NOTICE=50"}},
+		{name: "bad type name", pkg: "levels", typeName: "my-logger", args: []string{"NOTICE=50"}},
+		{name: "no levels", pkg: "levels", typeName: "Logger"},
+		{name: "missing severity", pkg: "levels", typeName: "Logger", args: []string{"NOTICE"}},
+		{name: "severity not an integer", pkg: "levels", typeName: "Logger", args: []string{"NOTICE=high"}},
+		{name: "name without letters", pkg: "levels", typeName: "Logger", args: []string{"123=50"}},
+		{name: "same level twice", pkg: "levels", typeName: "Logger", args: []string{"NOTICE=50", "notice=60"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			output := filepath.Join(t.TempDir(), "jellog_levels.go")
+
+			err := run(output, tc.pkg, tc.typeName, true, tc.args)
+
+			if err == nil {
+				t.Errorf("expected error")
+			}
+			if _, statErr := os.Stat(output); statErr == nil {
+				t.Errorf("expected no output to be written")
+			}
+		})
+	}
+}
+
+// Test_generatedCode generates levels into a temporary module that uses this
+// copy of jellog, and checks that the result passes go vet and that its
+// logging methods and functions report the caller of the method as the source
+// of the event.
+func Test_generatedCode(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a separate module")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not available")
+	}
+	root, err := filepath.Abs(filepath.Join("..", ".."))
+	if err != nil {
+		t.Fatalf("find module root: %v", err)
+	}
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/levels\n\ngo 1.19\n\n" +
+			"require github.com/dekarrin/jellog v0.0.0\n\n" +
+			"replace github.com/dekarrin/jellog => " + root + "\n",
+		"levels_test.go": generatedCodeTest,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	if err := run(filepath.Join(dir, "jellog_levels.go"), "levels", "Logger", true, []string{"NOTICE=50,note"}); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	for _, args := range [][]string{{"vet", "./..."}, {"test", "./..."}} {
+		cmd := exec.Command(goBin, args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GOWORK=off", "GOFLAGS=-mod=mod", "GOPROXY=off")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Errorf("go %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+}
+
+// generatedCodeTest is the test that Test_generatedCode runs in the module it
+// generates levels into.
+const generatedCodeTest = `package levels
+
+import (
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/dekarrin/jellog"
+	"github.com/dekarrin/jellog/jellogtest"
+)
+
+// callerLine returns the line that it is called from.
+func callerLine() int {
+	_, _, line, _ := runtime.Caller(1)
+	return line
+}
+
+func Test_levels(t *testing.T) {
+	rec := jellogtest.NewRecorder[string](nil)
+	base := jellog.New(jellog.Defaults[string]())
+	base.AddHandler(jellog.LvTrace, rec)
+	std := jellog.Default()
+	std.AddHandler(jellog.LvTrace, rec)
+	lg := WrapLogger(base)
+
+	// each returns the line that it logs from
+	logs := []func() int{
+		func() int { lg.Notice("a"); return callerLine() },
+		func() int { lg.Noticef("%s", "b"); return callerLine() },
+		func() int { Notice("c"); return callerLine() },
+		func() int { Noticef("%s", "d"); return callerLine() },
+	}
+	for i, log := range logs {
+		line := log()
+
+		evts := rec.Events()
+		if len(evts) != i+1 {
+			t.Fatalf("call %d: expected %d events, got %d", i, i+1, len(evts))
+		}
+		evt := evts[i]
+		if evt.Level.Name != "NOTICE" || evt.Level.Severity != 50 {
+			t.Errorf("call %d: expected level NOTICE with severity 50, got %v", i, evt.Level)
+		}
+		file, actualLine, _, _ := evt.Caller()
+		if filepath.Base(file) != "levels_test.go" || actualLine != line {
+			t.Errorf("call %d: expected caller levels_test.go:%d, got %s:%d", i, line, file, actualLine)
+		}
+	}
+
+	if lv, err := jellog.ParseLevel("note"); err != nil || lv.Severity != 50 {
+		t.Errorf("expected alias note to parse to NOTICE, got %v, %v", lv, err)
+	}
+}
+`
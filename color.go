@@ -0,0 +1,160 @@
+package jellog
+
+import (
+	"os"
+	"sync"
+)
+
+// ColorMode is whether a Formatter that supports it colorizes its output using
+// ANSI escape sequences.
+type ColorMode int
+
+const (
+	// ColorAuto colorizes output only when the Handler using the Formatter
+	// reports that its destination is a terminal. The environment variables
+	// FORCE_COLOR and NO_COLOR are honored in this mode; if FORCE_COLOR is set
+	// to anything other than "", "0", or "false", color is always used, and
+	// otherwise if NO_COLOR is set to anything other than "", color is never
+	// used.
+	ColorAuto ColorMode = iota
+
+	// ColorAlways always colorizes output, regardless of destination or
+	// environment.
+	ColorAlways
+
+	// ColorNever never colorizes output.
+	ColorNever
+)
+
+// Color is an ANSI SGR parameter sequence used to color part of a log entry,
+// such as "31" for red or "1;31" for bold red. The predefined Colors cover the
+// standard eight terminal colors and their bright variants, but any valid SGR
+// parameter string may be used.
+type Color string
+
+const (
+	ColorBlack   Color = "30"
+	ColorRed     Color = "31"
+	ColorGreen   Color = "32"
+	ColorYellow  Color = "33"
+	ColorBlue    Color = "34"
+	ColorMagenta Color = "35"
+	ColorCyan    Color = "36"
+	ColorWhite   Color = "37"
+
+	ColorBrightBlack   Color = "90"
+	ColorBrightRed     Color = "91"
+	ColorBrightGreen   Color = "92"
+	ColorBrightYellow  Color = "93"
+	ColorBrightBlue    Color = "94"
+	ColorBrightMagenta Color = "95"
+	ColorBrightCyan    Color = "96"
+	ColorBrightWhite   Color = "97"
+)
+
+// TerminalFormatter is a Formatter whose output can change depending on whether
+// it is being written to a terminal. Handlers whose destination may be a
+// terminal call ForTerminal to get the Formatter to actually use.
+type TerminalFormatter[E any] interface {
+	Formatter[E]
+
+	// ForTerminal returns the Formatter to use for a destination that is or
+	// is not a terminal.
+	ForTerminal(isTerminal bool) Formatter[E]
+}
+
+var (
+	levelColorsMtx sync.RWMutex
+	levelColors    = map[int]Color{
+		LvTrace.Severity: ColorBrightBlack,
+		LvDebug.Severity: ColorCyan,
+		LvInfo.Severity:  ColorGreen,
+		LvWarn.Severity:  ColorYellow,
+		LvError.Severity: ColorRed,
+		LvFatal.Severity: "1;" + ColorRed,
+	}
+
+	stderrTermOnce sync.Once
+	stderrIsTerm   bool
+)
+
+// SetLevelColor sets the color that the name of the given level is shown in
+// by Formatters that colorize their output. Levels are matched by severity, so
+// this can be used both to change the color of the built-in levels and to give
+// a custom level its own color. Giving "" as the color removes any color that
+// was set for the level.
+//
+// SetLevelColor is safe to call concurrently with logging.
+func SetLevelColor(lv Level, c Color) {
+	levelColorsMtx.Lock()
+	defer levelColorsMtx.Unlock()
+
+	if c == "" {
+		delete(levelColors, lv.Severity)
+	} else {
+		levelColors[lv.Severity] = c
+	}
+}
+
+// LevelColor returns the color that the name of the given level is shown in by
+// Formatters that colorize their output. If no color has been set for the
+// level, "" is returned.
+func LevelColor(lv Level) Color {
+	levelColorsMtx.RLock()
+	defer levelColorsMtx.RUnlock()
+
+	return levelColors[lv.Severity]
+}
+
+// colorEnabled resolves mode to whether color should actually be used for a
+// destination that is or is not a terminal.
+func colorEnabled(mode ColorMode, isTerminal bool) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+
+	if force := os.Getenv("FORCE_COLOR"); force != "" && force != "0" && force != "false" {
+		return true
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return isTerminal
+}
+
+// isTerminal returns whether f appears to be a terminal. This checks whether f
+// is a character device, which works across platforms without needing any
+// ioctls.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+// stderrIsTerminal returns whether stderr is a terminal. There is only one
+// stderr, so the result is only checked once.
+func stderrIsTerminal() bool {
+	stderrTermOnce.Do(func() {
+		stderrIsTerm = isTerminal(os.Stderr)
+	})
+	return stderrIsTerm
+}
+
+// appendColored appends s to buf, wrapped in the escape sequences for c. If c
+// is "", s is appended as-is.
+func appendColored(buf []byte, c Color, s string) []byte {
+	if c == "" {
+		return append(buf, s...)
+	}
+	buf = append(buf, "\x1b["...)
+	buf = append(buf, c...)
+	buf = append(buf, 'm')
+	buf = append(buf, s...)
+	buf = append(buf, "\x1b[0m"...)
+	return buf
+}
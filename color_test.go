@@ -0,0 +1,92 @@
+package jellog_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dekarrin/jellog"
+)
+
+func Test_LineFormat_ForTerminal(t *testing.T) {
+	testCases := []struct {
+		name        string
+		mode        jellog.ColorMode
+		forceColor  string
+		noColor     string
+		terminal    bool
+		expectColor bool
+	}{
+		{name: "auto on terminal", terminal: true, expectColor: true},
+		{name: "auto not on terminal", terminal: false, expectColor: false},
+		{name: "NO_COLOR on terminal", noColor: "1", terminal: true, expectColor: false},
+		{name: "FORCE_COLOR not on terminal", forceColor: "1", terminal: false, expectColor: true},
+		{name: "FORCE_COLOR beats NO_COLOR", forceColor: "1", noColor: "1", terminal: false, expectColor: true},
+		{name: "FORCE_COLOR=0 is unset", forceColor: "0", terminal: false, expectColor: false},
+		{name: "FORCE_COLOR=false is unset", forceColor: "false", noColor: "1", terminal: true, expectColor: false},
+		{name: "always ignores NO_COLOR", mode: jellog.ColorAlways, noColor: "1", terminal: false, expectColor: true},
+		{name: "never ignores FORCE_COLOR", mode: jellog.ColorNever, forceColor: "1", terminal: true, expectColor: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("FORCE_COLOR", tc.forceColor)
+			t.Setenv("NO_COLOR", tc.noColor)
+			lf := jellog.LineFormat{Color: tc.mode, OmitTime: true}
+
+			out := string(lf.ForTerminal(tc.terminal).Format(jellog.Event[string]{Level: jellog.LvWarn, Message: "a"}))
+
+			colored := strings.Contains(out, "\x1b[")
+			if colored != tc.expectColor {
+				t.Errorf("expected color to be %v, got %q", tc.expectColor, out)
+			}
+		})
+	}
+}
+
+func Test_LineFormat_Format_color(t *testing.T) {
+	custom := jellog.Level{Name: "AUDIT", Severity: 250}
+	jellog.SetLevelColor(custom, jellog.ColorMagenta)
+	defer jellog.SetLevelColor(custom, "")
+
+	testCases := []struct {
+		name   string
+		format jellog.LineFormat
+		evt    jellog.Event[string]
+		expect string
+	}{
+		{
+			name:   "built-in level",
+			format: jellog.LineFormat{Color: jellog.ColorAlways, OmitTime: true},
+			evt:    jellog.Event[string]{Level: jellog.LvError, Message: "a"},
+			expect: "\x1b[31mERROR\x1b[0m a\n",
+		},
+		{
+			name:   "custom level",
+			format: jellog.LineFormat{Color: jellog.ColorAlways, OmitTime: true},
+			evt:    jellog.Event[string]{Level: custom, Message: "a"},
+			expect: "\x1b[35mAUDIT\x1b[0m a\n",
+		},
+		{
+			name:   "component",
+			format: jellog.LineFormat{Color: jellog.ColorAlways, OmitTime: true, ComponentColor: jellog.ColorCyan},
+			evt:    jellog.Event[string]{Level: jellog.LvInfo, Component: "db", Message: "a"},
+			expect: "\x1b[32mINFO\x1b[0m  (\x1b[36mdb\x1b[0m) a\n",
+		},
+		{
+			name:   "never",
+			format: jellog.LineFormat{Color: jellog.ColorNever, OmitTime: true},
+			evt:    jellog.Event[string]{Level: jellog.LvError, Component: "db", Message: "a"},
+			expect: "ERROR (db) a\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := string(tc.format.Format(tc.evt))
+
+			if actual != tc.expect {
+				t.Errorf("expected %q, got %q", tc.expect, actual)
+			}
+		})
+	}
+}
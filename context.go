@@ -0,0 +1,308 @@
+package jellog
+
+import (
+	"context"
+	"fmt"
+)
+
+// loggerContextKey is the key a Logger[E] is stored under in a context. It is
+// generic so that Loggers of different types do not collide.
+type loggerContextKey[E any] struct{}
+
+// NewContext returns a copy of ctx that carries lg. Use FromContext to retrieve
+// it. A nil ctx is treated as context.Background().
+func NewContext[E any](ctx context.Context, lg Logger[E]) context.Context {
+	return context.WithValue(nonNilContext(ctx), loggerContextKey[E]{}, lg)
+}
+
+// FromContext returns the Logger carried by ctx. If ctx does not carry a Logger
+// of the given type, the default logger is returned when E is string; for any
+// other E, a new Logger with default options and no Handlers is returned. A
+// nil ctx carries no Logger.
+func FromContext[E any](ctx context.Context) Logger[E] {
+	if lg, ok := nonNilContext(ctx).Value(loggerContextKey[E]{}).(Logger[E]); ok {
+		return lg
+	}
+	if lg, ok := any(std).(Logger[E]); ok {
+		return lg
+	}
+	return New(Defaults[E]())
+}
+
+// ContextExtractor retrieves values from a context to be added to a log event
+// as Attrs. Extractors are configured with Options.Extractors and are called by
+// CreateEventCtx, which all of the context-aware logging methods use. They are
+// never given a nil context by a Logger.
+type ContextExtractor func(ctx context.Context) []Attr
+
+// ExtractValue returns a ContextExtractor that adds the value stored in a
+// context under key as an Attr named name. If the context has no value for
+// key or is nil, no Attr is added.
+func ExtractValue(key any, name string) ContextExtractor {
+	return func(ctx context.Context) []Attr {
+		v := nonNilContext(ctx).Value(key)
+		if v == nil {
+			return nil
+		}
+		return []Attr{{Key: name, Value: v}}
+	}
+}
+
+// forcedLevelContextKey is the key that a forced Level is stored under in a
+// context.
+type forcedLevelContextKey struct{}
+
+// WithForcedLevel returns a copy of ctx that forces events logged with it to be
+// sent to Handlers that would otherwise not receive them because of their
+// minimum level. Every event logged with the returned context that is at level
+// lv or higher will be routed to all Handlers that have
+// HandlerOptions.HonorForcedLevel set, regardless of the level they were added
+// at. Handlers without HonorForcedLevel set are not affected.
+//
+// This is intended for temporarily increasing the verbosity of logging for a
+// single request or operation, such as while debugging an incident, without
+// changing the levels of Handlers for everything else. A nil ctx is treated as
+// context.Background().
+func WithForcedLevel(ctx context.Context, lv Level) context.Context {
+	return context.WithValue(nonNilContext(ctx), forcedLevelContextKey{}, lv)
+}
+
+// ForcedLevel returns the Level set on ctx with WithForcedLevel. If ctx has no
+// forced level or is nil, ok will be false.
+func ForcedLevel(ctx context.Context) (lv Level, ok bool) {
+	lv, ok = nonNilContext(ctx).Value(forcedLevelContextKey{}).(Level)
+	return lv, ok
+}
+
+// nonNilContext returns ctx, or context.Background() if ctx is nil.
+func nonNilContext(ctx context.Context) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+	return ctx
+}
+
+// CreateEventCtx creates an Event in the same way as CreateEvent and then adds
+// to it the Attrs returned by each of the Logger's ContextExtractors when
+// called with ctx. The returned Event carries ctx, which is used for routing
+// events logged with a context created by WithForcedLevel.
+//
+// A nil ctx is treated as context.Background(), both here and in all of the
+// context-aware logging methods and functions.
+func (lg Logger[E]) CreateEventCtx(ctx context.Context, lv Level, msg any) Event[E] {
+	ctx = nonNilContext(ctx)
+	evt := lg.CreateEvent(lv, msg)
+	evt.ctx = ctx
+	for _, ext := range lg.opts.Extractors {
+		evt.Attrs = append(evt.Attrs, ext(ctx)...)
+	}
+	return evt
+}
+
+// logCtx creates an event from msg and ctx and outputs it, passing any error to
+// the Logger's error policy. It is the shared implementation of the
+// context-aware logging methods of Logger and the package-level logging
+// functions, which call it on the Logger from FromContext, with a calldepth of
+// 2.
+func (lg Logger[E]) logCtx(calldepth int, ctx context.Context, lv Level, msg any) {
+	evt := lg.CreateEventCtx(ctx, lv, msg)
+	lg.HandleError(lg.Output(calldepth+1, evt))
+}
+
+// LogCtx logs a message at the given severity level with values from ctx.
+// Supplementary information is gathered along with msg into an Event which is
+// then passed to the appropriate Handlers.
+//
+// If msg is of type E, then it is used directly. If it is not, it is converted
+// to the proper type by using the Logger's Converter function.
+func (lg Logger[E]) LogCtx(ctx context.Context, lv Level, msg any) {
+	lg.logCtx(2, ctx, lv, msg)
+}
+
+// LogfCtx logs a formatted message at the given severity level with values from
+// ctx. Supplementary information is gathered along with msg into an Event which
+// is then passed to the appropriate Handlers.
+//
+// The message is created as a formatted string, and is then converted to the
+// type of logged object handled by the Logger by using the Logger's Converter
+// function.
+func (lg Logger[E]) LogfCtx(ctx context.Context, lv Level, msg string, a ...interface{}) {
+	lg.logCtx(2, ctx, lv, fmt.Sprintf(msg, a...))
+}
+
+// TraceCtx logs a message at severity level TRACE with values from ctx.
+// Supplementary information is gathered along with msg into an Event which is
+// then passed to the appropriate Handlers.
+//
+// If msg is of type E, then it is used directly. If it is not, it is converted
+// to the proper type by using the Logger's Converter function.
+func (lg Logger[E]) TraceCtx(ctx context.Context, msg E) {
+	lg.logCtx(2, ctx, LvTrace, msg)
+}
+
+// TracefCtx logs a formatted message at severity level TRACE with values from
+// ctx. Supplementary information is gathered along with msg into an Event which
+// is then passed to the appropriate Handlers.
+//
+// The message is created as a formatted string, and is then converted to the
+// type of logged object handled by the Logger by using the Logger's Converter
+// function.
+func (lg Logger[E]) TracefCtx(ctx context.Context, msg string, a ...interface{}) {
+	lg.logCtx(2, ctx, LvTrace, fmt.Sprintf(msg, a...))
+}
+
+// DebugCtx logs a message at severity level DEBUG with values from ctx.
+// Supplementary information is gathered along with msg into an Event which is
+// then passed to the appropriate Handlers.
+//
+// If msg is of type E, then it is used directly. If it is not, it is converted
+// to the proper type by using the Logger's Converter function.
+func (lg Logger[E]) DebugCtx(ctx context.Context, msg E) {
+	lg.logCtx(2, ctx, LvDebug, msg)
+}
+
+// DebugfCtx logs a formatted message at severity level DEBUG with values from
+// ctx. Supplementary information is gathered along with msg into an Event which
+// is then passed to the appropriate Handlers.
+//
+// The message is created as a formatted string, and is then converted to the
+// type of logged object handled by the Logger by using the Logger's Converter
+// function.
+func (lg Logger[E]) DebugfCtx(ctx context.Context, msg string, a ...interface{}) {
+	lg.logCtx(2, ctx, LvDebug, fmt.Sprintf(msg, a...))
+}
+
+// InfoCtx logs a message at severity level INFO with values from ctx.
+// Supplementary information is gathered along with msg into an Event which is
+// then passed to the appropriate Handlers.
+//
+// If msg is of type E, then it is used directly. If it is not, it is converted
+// to the proper type by using the Logger's Converter function.
+func (lg Logger[E]) InfoCtx(ctx context.Context, msg E) {
+	lg.logCtx(2, ctx, LvInfo, msg)
+}
+
+// InfofCtx logs a formatted message at severity level INFO with values from
+// ctx. Supplementary information is gathered along with msg into an Event which
+// is then passed to the appropriate Handlers.
+//
+// The message is created as a formatted string, and is then converted to the
+// type of logged object handled by the Logger by using the Logger's Converter
+// function.
+func (lg Logger[E]) InfofCtx(ctx context.Context, msg string, a ...interface{}) {
+	lg.logCtx(2, ctx, LvInfo, fmt.Sprintf(msg, a...))
+}
+
+// WarnCtx logs a message at severity level WARN with values from ctx.
+// Supplementary information is gathered along with msg into an Event which is
+// then passed to the appropriate Handlers.
+//
+// If msg is of type E, then it is used directly. If it is not, it is converted
+// to the proper type by using the Logger's Converter function.
+func (lg Logger[E]) WarnCtx(ctx context.Context, msg E) {
+	lg.logCtx(2, ctx, LvWarn, msg)
+}
+
+// WarnfCtx logs a formatted message at severity level WARN with values from
+// ctx. Supplementary information is gathered along with msg into an Event which
+// is then passed to the appropriate Handlers.
+//
+// The message is created as a formatted string, and is then converted to the
+// type of logged object handled by the Logger by using the Logger's Converter
+// function.
+func (lg Logger[E]) WarnfCtx(ctx context.Context, msg string, a ...interface{}) {
+	lg.logCtx(2, ctx, LvWarn, fmt.Sprintf(msg, a...))
+}
+
+// ErrorCtx logs a message at severity level ERROR with values from ctx.
+// Supplementary information is gathered along with msg into an Event which is
+// then passed to the appropriate Handlers.
+//
+// If msg is of type E, then it is used directly. If it is not, it is converted
+// to the proper type by using the Logger's Converter function.
+func (lg Logger[E]) ErrorCtx(ctx context.Context, msg E) {
+	lg.logCtx(2, ctx, LvError, msg)
+}
+
+// ErrorfCtx logs a formatted message at severity level ERROR with values from
+// ctx. Supplementary information is gathered along with msg into an Event which
+// is then passed to the appropriate Handlers.
+//
+// The message is created as a formatted string, and is then converted to the
+// type of logged object handled by the Logger by using the Logger's Converter
+// function.
+func (lg Logger[E]) ErrorfCtx(ctx context.Context, msg string, a ...interface{}) {
+	lg.logCtx(2, ctx, LvError, fmt.Sprintf(msg, a...))
+}
+
+// LogCtx logs a message at the specified severity level using the Logger
+// carried by ctx, or the default logger if ctx does not carry one.
+func LogCtx(ctx context.Context, lv Level, msg string) {
+	FromContext[string](ctx).logCtx(2, ctx, lv, msg)
+}
+
+// LogfCtx logs a formatted message at the specified severity level using the
+// Logger carried by ctx, or the default logger if ctx does not carry one.
+func LogfCtx(ctx context.Context, lv Level, msg string, a ...interface{}) {
+	FromContext[string](ctx).logCtx(2, ctx, lv, fmt.Sprintf(msg, a...))
+}
+
+// TraceCtx logs a message with severity level TRACE using the Logger carried by
+// ctx, or the default logger if ctx does not carry one.
+func TraceCtx(ctx context.Context, msg string) {
+	FromContext[string](ctx).logCtx(2, ctx, LvTrace, msg)
+}
+
+// TracefCtx logs a formatted message with severity level TRACE using the Logger
+// carried by ctx, or the default logger if ctx does not carry one.
+func TracefCtx(ctx context.Context, msg string, a ...interface{}) {
+	FromContext[string](ctx).logCtx(2, ctx, LvTrace, fmt.Sprintf(msg, a...))
+}
+
+// DebugCtx logs a message with severity level DEBUG using the Logger carried by
+// ctx, or the default logger if ctx does not carry one.
+func DebugCtx(ctx context.Context, msg string) {
+	FromContext[string](ctx).logCtx(2, ctx, LvDebug, msg)
+}
+
+// DebugfCtx logs a formatted message with severity level DEBUG using the Logger
+// carried by ctx, or the default logger if ctx does not carry one.
+func DebugfCtx(ctx context.Context, msg string, a ...interface{}) {
+	FromContext[string](ctx).logCtx(2, ctx, LvDebug, fmt.Sprintf(msg, a...))
+}
+
+// InfoCtx logs a message with severity level INFO using the Logger carried by
+// ctx, or the default logger if ctx does not carry one.
+func InfoCtx(ctx context.Context, msg string) {
+	FromContext[string](ctx).logCtx(2, ctx, LvInfo, msg)
+}
+
+// InfofCtx logs a formatted message with severity level INFO using the Logger
+// carried by ctx, or the default logger if ctx does not carry one.
+func InfofCtx(ctx context.Context, msg string, a ...interface{}) {
+	FromContext[string](ctx).logCtx(2, ctx, LvInfo, fmt.Sprintf(msg, a...))
+}
+
+// WarnCtx logs a message with severity level WARN using the Logger carried by
+// ctx, or the default logger if ctx does not carry one.
+func WarnCtx(ctx context.Context, msg string) {
+	FromContext[string](ctx).logCtx(2, ctx, LvWarn, msg)
+}
+
+// WarnfCtx logs a formatted message with severity level WARN using the Logger
+// carried by ctx, or the default logger if ctx does not carry one.
+func WarnfCtx(ctx context.Context, msg string, a ...interface{}) {
+	FromContext[string](ctx).logCtx(2, ctx, LvWarn, fmt.Sprintf(msg, a...))
+}
+
+// ErrorCtx logs a message with severity level ERROR using the Logger carried by
+// ctx, or the default logger if ctx does not carry one.
+func ErrorCtx(ctx context.Context, msg string) {
+	FromContext[string](ctx).logCtx(2, ctx, LvError, msg)
+}
+
+// ErrorfCtx logs a formatted message with severity level ERROR using the Logger
+// carried by ctx, or the default logger if ctx does not carry one.
+func ErrorfCtx(ctx context.Context, msg string, a ...interface{}) {
+	FromContext[string](ctx).logCtx(2, ctx, LvError, fmt.Sprintf(msg, a...))
+}
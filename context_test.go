@@ -0,0 +1,118 @@
+package jellog_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/dekarrin/jellog"
+	"github.com/dekarrin/jellog/jellogtest"
+)
+
+type requestIDKey struct{}
+
+func Test_Logger_ctxMethods(t *testing.T) {
+	testCases := []struct {
+		name   string
+		log    func(lg jellog.Logger[string], ctx context.Context)
+		expect jellog.Level
+	}{
+		{name: "LogCtx", log: func(lg jellog.Logger[string], ctx context.Context) { lg.LogCtx(ctx, jellog.LvWarn, "msg") }, expect: jellog.LvWarn},
+		{name: "LogfCtx", log: func(lg jellog.Logger[string], ctx context.Context) { lg.LogfCtx(ctx, jellog.LvWarn, "m%s", "sg") }, expect: jellog.LvWarn},
+		{name: "TraceCtx", log: func(lg jellog.Logger[string], ctx context.Context) { lg.TraceCtx(ctx, "msg") }, expect: jellog.LvTrace},
+		{name: "TracefCtx", log: func(lg jellog.Logger[string], ctx context.Context) { lg.TracefCtx(ctx, "m%s", "sg") }, expect: jellog.LvTrace},
+		{name: "DebugCtx", log: func(lg jellog.Logger[string], ctx context.Context) { lg.DebugCtx(ctx, "msg") }, expect: jellog.LvDebug},
+		{name: "DebugfCtx", log: func(lg jellog.Logger[string], ctx context.Context) { lg.DebugfCtx(ctx, "m%s", "sg") }, expect: jellog.LvDebug},
+		{name: "InfoCtx", log: func(lg jellog.Logger[string], ctx context.Context) { lg.InfoCtx(ctx, "msg") }, expect: jellog.LvInfo},
+		{name: "InfofCtx", log: func(lg jellog.Logger[string], ctx context.Context) { lg.InfofCtx(ctx, "m%s", "sg") }, expect: jellog.LvInfo},
+		{name: "WarnCtx", log: func(lg jellog.Logger[string], ctx context.Context) { lg.WarnCtx(ctx, "msg") }, expect: jellog.LvWarn},
+		{name: "WarnfCtx", log: func(lg jellog.Logger[string], ctx context.Context) { lg.WarnfCtx(ctx, "m%s", "sg") }, expect: jellog.LvWarn},
+		{name: "ErrorCtx", log: func(lg jellog.Logger[string], ctx context.Context) { lg.ErrorCtx(ctx, "msg") }, expect: jellog.LvError},
+		{name: "ErrorfCtx", log: func(lg jellog.Logger[string], ctx context.Context) { lg.ErrorfCtx(ctx, "m%s", "sg") }, expect: jellog.LvError},
+
+		{name: "package LogCtx", log: func(lg jellog.Logger[string], ctx context.Context) { jellog.LogCtx(ctx, jellog.LvWarn, "msg") }, expect: jellog.LvWarn},
+		{name: "package LogfCtx", log: func(lg jellog.Logger[string], ctx context.Context) { jellog.LogfCtx(ctx, jellog.LvWarn, "m%s", "sg") }, expect: jellog.LvWarn},
+		{name: "package TraceCtx", log: func(lg jellog.Logger[string], ctx context.Context) { jellog.TraceCtx(ctx, "msg") }, expect: jellog.LvTrace},
+		{name: "package TracefCtx", log: func(lg jellog.Logger[string], ctx context.Context) { jellog.TracefCtx(ctx, "m%s", "sg") }, expect: jellog.LvTrace},
+		{name: "package DebugCtx", log: func(lg jellog.Logger[string], ctx context.Context) { jellog.DebugCtx(ctx, "msg") }, expect: jellog.LvDebug},
+		{name: "package DebugfCtx", log: func(lg jellog.Logger[string], ctx context.Context) { jellog.DebugfCtx(ctx, "m%s", "sg") }, expect: jellog.LvDebug},
+		{name: "package InfoCtx", log: func(lg jellog.Logger[string], ctx context.Context) { jellog.InfoCtx(ctx, "msg") }, expect: jellog.LvInfo},
+		{name: "package InfofCtx", log: func(lg jellog.Logger[string], ctx context.Context) { jellog.InfofCtx(ctx, "m%s", "sg") }, expect: jellog.LvInfo},
+		{name: "package WarnCtx", log: func(lg jellog.Logger[string], ctx context.Context) { jellog.WarnCtx(ctx, "msg") }, expect: jellog.LvWarn},
+		{name: "package WarnfCtx", log: func(lg jellog.Logger[string], ctx context.Context) { jellog.WarnfCtx(ctx, "m%s", "sg") }, expect: jellog.LvWarn},
+		{name: "package ErrorCtx", log: func(lg jellog.Logger[string], ctx context.Context) { jellog.ErrorCtx(ctx, "msg") }, expect: jellog.LvError},
+		{name: "package ErrorfCtx", log: func(lg jellog.Logger[string], ctx context.Context) { jellog.ErrorfCtx(ctx, "m%s", "sg") }, expect: jellog.LvError},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := jellogtest.NewRecorder[string](nil)
+			opts := jellog.Defaults[string]().WithExtractor(jellog.ExtractValue(requestIDKey{}, "request_id"))
+			lg := jellog.New(opts)
+			lg.AddHandler(jellog.LvTrace, rec)
+
+			// the package-level functions find the Logger in the context
+			ctx := context.WithValue(context.Background(), requestIDKey{}, "r-1")
+			ctx = jellog.NewContext(ctx, lg)
+
+			tc.log(lg, ctx)
+
+			evts := rec.Events()
+			if len(evts) != 1 {
+				t.Fatalf("expected 1 event, got %d", len(evts))
+			}
+			evt := evts[0]
+			if evt.Level.Severity != tc.expect.Severity || evt.Message != "msg" {
+				t.Errorf("expected %s event with message %q, got %s event with message %q", tc.expect, "msg", evt.Level, evt.Message)
+			}
+			if evt.Context() != ctx {
+				t.Errorf("expected event to carry the context it was logged with")
+			}
+			if len(evt.Attrs) != 1 || evt.Attrs[0].Key != "request_id" || evt.Attrs[0].Value != "r-1" {
+				t.Errorf("expected attr request_id=r-1 from context, got %v", evt.Attrs)
+			}
+			if file, _, _, _ := evt.Caller(); filepath.Base(file) != "context_test.go" {
+				t.Errorf("expected caller to be in context_test.go, got %q", file)
+			}
+		})
+	}
+}
+
+func Test_nilContext(t *testing.T) {
+	var nilCtx context.Context
+
+	rec := jellogtest.NewRecorder[string](nil)
+	opts := jellog.Defaults[string]().WithExtractor(jellog.ExtractValue(requestIDKey{}, "request_id"))
+	lg := jellog.New(opts)
+	lg.AddHandler(jellog.LvTrace, rec)
+
+	lg.InfoCtx(nilCtx, "method")
+	jellogtest.AssertHasEvent(t, rec, jellog.LvInfo, "method")
+	if evts := rec.Events(); len(evts) == 1 && evts[0].Context() == nil {
+		t.Errorf("expected event logged with nil context to have a non-nil context")
+	}
+
+	// with no Logger in the context, the package-level functions use the
+	// default logger
+	stdRec := jellogtest.NewRecorder[string](nil)
+	std := jellog.Default()
+	std.AddHandler(jellog.LvTrace, stdRec)
+	jellog.TraceCtx(nilCtx, "package function with nil context")
+	jellogtest.AssertHasEvent(t, stdRec, jellog.LvTrace, "package function with nil context")
+
+	if actual := jellog.FromContext[int](nilCtx); len(actual.HandlersForLevel(jellog.LvFatal)) != 0 {
+		t.Errorf("expected Logger from nil context to have no Handlers")
+	}
+	if attrs := jellog.ExtractValue(requestIDKey{}, "request_id")(nilCtx); attrs != nil {
+		t.Errorf("expected no attrs from nil context, got %v", attrs)
+	}
+	if _, ok := jellog.ForcedLevel(nilCtx); ok {
+		t.Errorf("expected nil context to have no forced level")
+	}
+	if lv, ok := jellog.ForcedLevel(jellog.WithForcedLevel(nilCtx, jellog.LvDebug)); !ok || lv.Severity != jellog.LvDebug.Severity {
+		t.Errorf("expected forced level DEBUG, got %v (%v)", lv, ok)
+	}
+	if actual := jellog.FromContext[string](jellog.NewContext(nilCtx, lg)); len(actual.HandlersForLevel(jellog.LvTrace)) != 1 {
+		t.Errorf("expected Logger stored in nil context to be found")
+	}
+}
@@ -0,0 +1,211 @@
+package jellog
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// DedupOptions is used to control the behavior of a DedupHandler. It is passed
+// to NewDedupHandler as an optional argument.
+type DedupOptions[E any] struct {
+	// Timeout is how long repeats of an event are collected for before the
+	// event reporting how many times it was repeated is emitted, even if no
+	// different event has arrived. If not set, 30 seconds is used.
+	Timeout time.Duration
+
+	// Equal returns whether two events are repeats of each other. If not set,
+	// events are considered repeats if they have the same level severity,
+	// component, and message. Messages are compared with == if E is string and
+	// with reflect.DeepEqual otherwise.
+	Equal func(a, b Event[E]) bool
+
+	// Repeated creates the message of the event that reports how many times
+	// the previous event was repeated. It is given the number of repeats and
+	// the most recent of them. If not set, the message is created by
+	// converting a string in the style of syslogd's "last message repeated N
+	// times" using the same default converter as a Logger. The event is
+	// marked Converted if the most recent repeat was, as the message may
+	// include it.
+	Repeated func(count int, last Event[E]) E
+
+	// ErrorHandler is called with the error returned by the wrapped Handler
+	// when it fails to output a summary that is emitted because Timeout
+	// elapsed, as there is no caller to return the error to then. Errors from
+	// outputting events and summaries during a call to Output are returned from
+	// it as usual. If not set, these errors are reported to stderr at a limited
+	// rate, as they are for a Logger without an ErrorHandler. To apply a
+	// Logger's own error policy, give its HandleError method.
+	ErrorHandler func(err error)
+}
+
+// DedupHandler is a Handler that wraps another Handler and collapses
+// consecutive identical events into one, as syslogd does. The first of a run of
+// identical events is passed through immediately and the rest are counted.
+// When a different event arrives or the configured timeout elapses, a single
+// event reporting how many times the previous one was repeated is passed
+// through in their place.
+//
+// A DedupHandler is safe to use from multiple goroutines. It should be created
+// with NewDedupHandler.
+type DedupHandler[E any] struct {
+	h    Handler[E]
+	opts DedupOptions[E]
+
+	mtx      sync.Mutex
+	prev     Event[E]
+	hasPrev  bool
+	repeats  int
+	last     Event[E]
+	timer    *time.Timer
+	timerGen int
+	errs     errorState
+}
+
+// NewDedupHandler creates a DedupHandler that passes events through to h.
+//
+// To use the default set of DedupOptions, pass nil for opts.
+func NewDedupHandler[E any](h Handler[E], opts *DedupOptions[E]) *DedupHandler[E] {
+	var o DedupOptions[E]
+	if opts != nil {
+		o = *opts
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = 30 * time.Second
+	}
+	if o.Equal == nil {
+		o.Equal = defaultDedupEqual[E]
+	}
+	if o.Repeated == nil {
+		conv := defaultConverter[E]()
+		o.Repeated = func(count int, last Event[E]) E {
+			if count == 1 {
+				return conv("previous message repeated 1 time")
+			}
+			return conv(fmt.Sprintf("previous message repeated %d times", count))
+		}
+	}
+
+	return &DedupHandler[E]{h: h, opts: o}
+}
+
+// HandlerOptions returns the options of the Handler that dh wraps.
+func (dh *DedupHandler[E]) HandlerOptions() HandlerOptions[E] {
+	return dh.h.HandlerOptions()
+}
+
+// InsertBreak inserts a break in the Handler that dh wraps.
+func (dh *DedupHandler[E]) InsertBreak() error {
+	return dh.h.InsertBreak()
+}
+
+// Output passes a log event through to the wrapped Handler unless it is a
+// repeat of the previous event, in which case it is counted and nil is
+// returned. If there were repeats of the previous event and evt is not one,
+// the event reporting them is passed through first.
+//
+// The calldepth argument is used for recovering the program counter. It should
+// be supplied with the number of levels into the jellog package that the caller
+// has reached, with the externally called function counting as 1.
+func (dh *DedupHandler[E]) Output(calldepth int, evt Event[E]) error {
+	if evt.PC == 0 {
+		evt.PC = callerPC(calldepth)
+	}
+
+	dh.mtx.Lock()
+	if dh.hasPrev && dh.opts.Equal(dh.prev, evt) {
+		dh.repeats++
+		dh.last = evt
+		if dh.timer == nil {
+			gen := dh.timerGen
+			dh.timer = time.AfterFunc(dh.opts.Timeout, func() { dh.timerFired(gen) })
+		}
+		dh.mtx.Unlock()
+		return nil
+	}
+
+	summary, hasSummary := dh.takeSummary()
+	dh.prev = evt
+	dh.hasPrev = true
+	dh.mtx.Unlock()
+
+	var fullErr error
+	if hasSummary {
+		fullErr = joinHandlerErr(fullErr, dh.h.Output(calldepth+1, summary))
+	}
+	return joinHandlerErr(fullErr, dh.h.Output(calldepth+1, evt))
+}
+
+// timerFired is called when the timeout for collecting repeats elapses.
+func (dh *DedupHandler[E]) timerFired(gen int) {
+	dh.mtx.Lock()
+	if gen != dh.timerGen {
+		// a different event arrived and stopped us after we already fired
+		dh.mtx.Unlock()
+		return
+	}
+	summary, hasSummary := dh.takeSummary()
+	dh.mtx.Unlock()
+
+	if hasSummary {
+		dh.errs.handle(dh.opts.ErrorHandler, joinHandlerErr(nil, dh.h.Output(1, summary)))
+	}
+}
+
+// takeSummary returns the event reporting the current repeats, if there are
+// any, and resets the count. dh.mtx must be held.
+func (dh *DedupHandler[E]) takeSummary() (Event[E], bool) {
+	if dh.timer != nil {
+		dh.timer.Stop()
+		dh.timer = nil
+	}
+	dh.timerGen++
+
+	if dh.repeats == 0 {
+		return Event[E]{}, false
+	}
+
+	summary := Event[E]{
+		Time:      time.Now(),
+		Level:     dh.last.Level,
+		Component: dh.last.Component,
+		PC:        dh.last.PC,
+		Attrs:     []Attr{{Key: "repeated", Value: dh.repeats}},
+		Converted: dh.last.Converted,
+		Message:   dh.opts.Repeated(dh.repeats, dh.last),
+		ctx:       dh.last.ctx,
+	}
+	dh.repeats = 0
+	return summary, true
+}
+
+func defaultDedupEqual[E any](a, b Event[E]) bool {
+	if a.Level.Severity != b.Level.Severity || a.Component != b.Component {
+		return false
+	}
+	if aStr, ok := any(a.Message).(string); ok {
+		return aStr == any(b.Message).(string)
+	}
+	return reflect.DeepEqual(a.Message, b.Message)
+}
+
+// WrappedHandlers returns the Handler that dh wraps.
+func (dh *DedupHandler[E]) WrappedHandlers() []Handler[E] {
+	return []Handler[E]{dh.h}
+}
+
+// Flush immediately passes through the event reporting repeats of the previous
+// event, if there have been any since it was last reported. It does not flush
+// the wrapped Handler; use FlushHandlers or Logger.Flush for that.
+func (dh *DedupHandler[E]) Flush(ctx context.Context) error {
+	dh.mtx.Lock()
+	summary, hasSummary := dh.takeSummary()
+	dh.mtx.Unlock()
+
+	if !hasSummary {
+		return nil
+	}
+	return dh.h.Output(1, summary)
+}
@@ -0,0 +1,247 @@
+package jellog_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dekarrin/jellog"
+	"github.com/dekarrin/jellog/jellogtest"
+)
+
+func Test_DedupHandler_Output(t *testing.T) {
+	testCases := []struct {
+		name   string
+		opts   *jellog.DedupOptions[string]
+		evts   []jellog.Event[string]
+		expect []string
+	}{
+		{
+			name:   "different events all pass",
+			evts:   infoEvents("a", "b", "c"),
+			expect: []string{"a", "b", "c"},
+		},
+		{
+			name:   "repeats are reported before the next different event",
+			evts:   infoEvents("a", "a", "a", "b"),
+			expect: []string{"a", "previous message repeated 2 times", "b"},
+		},
+		{
+			name:   "single repeat",
+			evts:   infoEvents("a", "a", "b"),
+			expect: []string{"a", "previous message repeated 1 time", "b"},
+		},
+		{
+			name:   "only consecutive events are repeats",
+			evts:   infoEvents("a", "b", "a"),
+			expect: []string{"a", "b", "a"},
+		},
+		{
+			name:   "pending repeats are held back",
+			evts:   infoEvents("a", "b", "b"),
+			expect: []string{"a", "b"},
+		},
+		{
+			name: "different levels are not repeats",
+			evts: []jellog.Event[string]{
+				{Level: jellog.LvInfo, Message: "a"},
+				{Level: jellog.LvWarn, Message: "a"},
+			},
+			expect: []string{"a", "a"},
+		},
+		{
+			name: "different components are not repeats",
+			evts: []jellog.Event[string]{
+				{Level: jellog.LvInfo, Component: "x", Message: "a"},
+				{Level: jellog.LvInfo, Component: "y", Message: "a"},
+			},
+			expect: []string{"a", "a"},
+		},
+		{
+			name: "custom Equal and Repeated",
+			opts: &jellog.DedupOptions[string]{
+				Equal: func(a, b jellog.Event[string]) bool {
+					return strings.HasPrefix(a.Message, "tick") && strings.HasPrefix(b.Message, "tick")
+				},
+				Repeated: func(count int, last jellog.Event[string]) string {
+					return "more ticks, last was " + last.Message
+				},
+			},
+			evts:   infoEvents("tick 1", "tick 2", "tick 3", "tock"),
+			expect: []string{"tick 1", "more ticks, last was tick 3", "tock"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := jellogtest.NewRecorder[string](nil)
+			dh := jellog.NewDedupHandler[string](rec, tc.opts)
+			defer dh.Flush(context.Background())
+
+			for _, evt := range tc.evts {
+				if err := dh.Output(1, evt); err != nil {
+					t.Fatalf("Output: %v", err)
+				}
+			}
+
+			actual := messages(rec.Events())
+			if strings.Join(actual, "|") != strings.Join(tc.expect, "|") {
+				t.Errorf("expected events %q, got %q", tc.expect, actual)
+			}
+		})
+	}
+}
+
+func Test_DedupHandler_summaryConverted(t *testing.T) {
+	testCases := []struct {
+		name      string
+		converted bool
+	}{
+		{name: "converted", converted: true},
+		{name: "not converted", converted: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := jellogtest.NewRecorder[string](nil)
+			dh := jellog.NewDedupHandler[string](rec, nil)
+
+			for i := 0; i < 3; i++ {
+				dh.Output(1, jellog.Event[string]{Level: jellog.LvInfo, Message: "a\nb", Converted: tc.converted})
+			}
+			if err := dh.Flush(context.Background()); err != nil {
+				t.Fatalf("Flush: %v", err)
+			}
+
+			evts := rec.Filter(jellogtest.HasAttr[string]("repeated"))
+			if len(evts) != 1 {
+				t.Fatalf("expected 1 repeat report, got %d", len(evts))
+			}
+			if evts[0].Converted != tc.converted {
+				t.Errorf("expected repeat report Converted to be %v, got %v", tc.converted, evts[0].Converted)
+			}
+		})
+	}
+}
+
+func Test_DedupHandler_timer(t *testing.T) {
+	rec := jellogtest.NewRecorder[string](nil)
+	dh := jellog.NewDedupHandler[string](rec, &jellog.DedupOptions[string]{Timeout: 20 * time.Millisecond})
+
+	for _, evt := range infoEvents("a", "a", "a") {
+		dh.Output(1, evt)
+	}
+
+	// the repeats are reported once the timeout elapses even though no
+	// different event arrives
+	repeated := jellogtest.HasAttr[string]("repeated")
+	waitFor(t, "repeat report", func() bool { return rec.Count(repeated) > 0 })
+
+	evts := rec.Filter(repeated)
+	if len(evts) != 1 || attrValue(evts[0], "repeated") != 2 {
+		t.Fatalf("expected 1 report of 2 repeats, got %v", messages(evts))
+	}
+
+	// the count starts over after a report
+	dh.Output(1, infoEvents("a")[0])
+	waitFor(t, "second repeat report", func() bool { return rec.Count(repeated) > 1 })
+	jellogtest.AssertHasEvent(t, rec, jellog.LvInfo, "previous message repeated 1 time")
+
+	// a report already made is not made again when the next different event
+	// arrives
+	dh.Output(1, infoEvents("b")[0])
+	actual := messages(rec.Events())
+	expect := []string{"a", "previous message repeated 2 times", "previous message repeated 1 time", "b"}
+	if strings.Join(actual, "|") != strings.Join(expect, "|") {
+		t.Errorf("expected events %q, got %q", expect, actual)
+	}
+}
+
+func Test_DedupHandler_summaryErrors(t *testing.T) {
+	errSummary := errors.New("cannot write summary")
+
+	testCases := []struct {
+		name           string
+		timeout        time.Duration
+		emit           func(dh *jellog.DedupHandler[string]) error
+		expectReturned bool
+	}{
+		{
+			name:    "timer reports to ErrorHandler",
+			timeout: 20 * time.Millisecond,
+			emit:    func(dh *jellog.DedupHandler[string]) error { return nil },
+		},
+		{
+			name:           "Flush returns error",
+			timeout:        time.Hour,
+			emit:           func(dh *jellog.DedupHandler[string]) error { return dh.Flush(context.Background()) },
+			expectReturned: true,
+		},
+		{
+			name:           "Output returns error",
+			timeout:        time.Hour,
+			emit:           func(dh *jellog.DedupHandler[string]) error { return dh.Output(1, infoEvents("b")[0]) },
+			expectReturned: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var mtx sync.Mutex
+			var reported []error
+
+			fh := &failingHandler{
+				Recorder: jellogtest.NewRecorder[string](nil),
+				fail:     jellogtest.HasAttr[string]("repeated"),
+				err:      errSummary,
+			}
+			dh := jellog.NewDedupHandler[string](fh, &jellog.DedupOptions[string]{
+				Timeout: tc.timeout,
+				ErrorHandler: func(err error) {
+					mtx.Lock()
+					defer mtx.Unlock()
+					reported = append(reported, err)
+				},
+			})
+
+			dh.Output(1, infoEvents("a")[0])
+			dh.Output(1, infoEvents("a")[0])
+
+			// errors are returned to a caller if there is one, and only given
+			// to ErrorHandler otherwise
+			err := tc.emit(dh)
+			if tc.expectReturned {
+				if !errors.Is(err, errSummary) {
+					t.Errorf("expected error to wrap %v, got %v", errSummary, err)
+				}
+				mtx.Lock()
+				defer mtx.Unlock()
+				if len(reported) > 0 {
+					t.Errorf("expected nothing to be reported to ErrorHandler, got %v", reported)
+				}
+				return
+			}
+			waitFor(t, "error to be reported", func() bool {
+				mtx.Lock()
+				defer mtx.Unlock()
+				return len(reported) > 0
+			})
+			mtx.Lock()
+			defer mtx.Unlock()
+			if !errors.Is(reported[0], errSummary) {
+				t.Errorf("expected reported error to wrap %v, got %v", errSummary, reported[0])
+			}
+		})
+	}
+}
+
+func infoEvents(msgs ...string) []jellog.Event[string] {
+	evts := make([]jellog.Event[string], len(msgs))
+	for i := range msgs {
+		evts[i] = jellog.Event[string]{Level: jellog.LvInfo, Message: msgs[i]}
+	}
+	return evts
+}
@@ -0,0 +1,368 @@
+package jellog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrWriteTimeout is returned (wrapped) when a Handler does not finish
+// outputting an event or break within the time it was given. It is returned by
+// WriteTimeoutHandler and by Loggers that use parallel dispatch with a
+// HandlerTimeout.
+var ErrWriteTimeout = errors.New("write timed out")
+
+// dispatchQueueSize is the number of events that can be waiting for a single
+// Handler before further events must wait to be queued.
+const dispatchQueueSize = 256
+
+// dispatchIdleTimeout is how long a dispatchWorker's goroutine waits for
+// another job before exiting. It is started again when the next job is queued,
+// so a worker that is no longer used does not keep its goroutine, or the
+// Handler it refers to, alive.
+const dispatchIdleTimeout = 30 * time.Second
+
+// dispatchJob is a single event or break to be sent to a Handler by a
+// dispatchWorker.
+type dispatchJob[E any] struct {
+	calldepth int
+	evt       Event[E]
+	brk       bool
+	done      chan error
+
+	// barrier is set for jobs that do nothing but report when every job
+	// queued before them is finished. If stop is also set, the worker's
+	// goroutine exits after reporting unless more jobs have been queued.
+	barrier bool
+	stop    bool
+}
+
+// dispatchWorker passes events to a single Handler one at a time, in the order
+// they were queued, from its own goroutine. The goroutine is only started once
+// there is a job for it and exits again once it has been idle for
+// dispatchIdleTimeout.
+//
+// A Logger has one dispatchWorker for each distinct Handler, which it shares
+// with its copies and uses for parallel dispatch; a WriteTimeoutHandler has one
+// for the Handler it wraps.
+type dispatchWorker[E any] struct {
+	h    Handler[E]
+	idle time.Duration
+
+	mtx     sync.Mutex
+	jobs    chan dispatchJob[E]
+	pending int
+	running bool
+}
+
+func newDispatchWorker[E any](h Handler[E]) *dispatchWorker[E] {
+	return &dispatchWorker[E]{h: h, idle: dispatchIdleTimeout}
+}
+
+// dispatchWorkers is the set of dispatchWorkers of a Logger and the Loggers
+// copied from it. Each distinct Handler has a single dispatchWorker no matter
+// how many times it is added, so it is still given events one at a time.
+type dispatchWorkers[E any] struct {
+	mtx sync.Mutex
+	ws  []*dispatchWorker[E]
+}
+
+// forHandler returns the dispatchWorker for h, creating it if h does not have
+// one yet. Handlers are matched with sameHandler, so a Handler whose type is
+// not comparable gets a new dispatchWorker each time.
+func (dws *dispatchWorkers[E]) forHandler(h Handler[E]) *dispatchWorker[E] {
+	dws.mtx.Lock()
+	defer dws.mtx.Unlock()
+
+	for _, w := range dws.ws {
+		if sameHandler(w.h, h) {
+			return w
+		}
+	}
+	w := newDispatchWorker(h)
+	dws.ws = append(dws.ws, w)
+	return w
+}
+
+// reserve records that a job is about to be queued, starting the worker's
+// goroutine if it is not running. It returns the queue to send the job on.
+func (w *dispatchWorker[E]) reserve() chan dispatchJob[E] {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	if w.jobs == nil {
+		w.jobs = make(chan dispatchJob[E], dispatchQueueSize)
+	}
+	w.pending++
+	if !w.running {
+		w.running = true
+		go w.run(w.jobs)
+	}
+	return w.jobs
+}
+
+// release records that a reserved job is finished or was never queued. If stop
+// is set and no other jobs are pending, the worker is marked as not running
+// and true is returned, in which case the calling goroutine must exit.
+func (w *dispatchWorker[E]) release(stop bool) bool {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	w.pending--
+	if stop && w.pending == 0 {
+		w.running = false
+		return true
+	}
+	return false
+}
+
+// exitIfIdle marks the worker as not running and returns true if no jobs are
+// pending, in which case the calling goroutine must exit.
+func (w *dispatchWorker[E]) exitIfIdle() bool {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	if w.pending == 0 {
+		w.running = false
+		return true
+	}
+	return false
+}
+
+func (w *dispatchWorker[E]) run(jobs chan dispatchJob[E]) {
+	idle := time.NewTimer(w.idle)
+	defer idle.Stop()
+
+	for {
+		select {
+		case job := <-jobs:
+			var err error
+			if job.brk {
+				err = w.h.InsertBreak()
+			} else if !job.barrier {
+				err = w.h.Output(job.calldepth, job.evt)
+			}
+			job.done <- err
+
+			if w.release(job.stop) {
+				return
+			}
+		case <-idle.C:
+			if w.exitIfIdle() {
+				return
+			}
+		}
+
+		if !idle.Stop() {
+			select {
+			case <-idle.C:
+			default:
+			}
+		}
+		idle.Reset(w.idle)
+	}
+}
+
+// enqueue queues an event or break for the Handler and returns the channel its
+// result will be sent on. If expired is closed before there is room in the
+// queue, it gives up and returns an error instead.
+func (w *dispatchWorker[E]) enqueue(calldepth int, evt Event[E], brk bool, expired <-chan struct{}) (chan error, error) {
+	job := dispatchJob[E]{
+		calldepth: calldepth,
+		evt:       evt,
+		brk:       brk,
+		done:      make(chan error, 1),
+	}
+
+	jobs := w.reserve()
+	select {
+	case jobs <- job:
+		return job.done, nil
+	case <-expired:
+		w.release(false)
+		return nil, fmt.Errorf("%w; queue is full and event was dropped", ErrWriteTimeout)
+	}
+}
+
+// drain waits until every job queued before it is finished, or until ctx is
+// done. If stop is set, the worker's goroutine exits once they are, rather
+// than waiting to become idle.
+func (w *dispatchWorker[E]) drain(ctx context.Context, stop bool) error {
+	// there is nothing to wait for if no jobs are pending, and nothing to
+	// stop if the goroutine is not running either
+	w.mtx.Lock()
+	skip := !w.running || (w.pending == 0 && !stop)
+	w.mtx.Unlock()
+	if skip {
+		return nil
+	}
+
+	job := dispatchJob[E]{barrier: true, stop: stop, done: make(chan error, 1)}
+
+	jobs := w.reserve()
+	select {
+	case jobs <- job:
+	case <-ctx.Done():
+		w.release(false)
+		return ctx.Err()
+	}
+
+	select {
+	case <-job.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// wait waits for the result of a job queued with enqueue. If expired is closed
+// first, an error is returned instead; the job is still completed later.
+func wait(done chan error, expired <-chan struct{}, timeout time.Duration) error {
+	// a Handler that finished in time may not have been waited on until after
+	// the deadline if an earlier one was slow, so check for a result first
+	select {
+	case err := <-done:
+		return err
+	default:
+	}
+
+	select {
+	case err := <-done:
+		return err
+	case <-expired:
+		return fmt.Errorf("%w after %s", ErrWriteTimeout, timeout)
+	}
+}
+
+// deadline returns a channel that is closed once timeout has elapsed, and a
+// function that stops the timer. If timeout is not positive, the returned
+// channel is nil and is never closed.
+func deadline(timeout time.Duration) (<-chan struct{}, func()) {
+	if timeout <= 0 {
+		return nil, func() {}
+	}
+	expired := make(chan struct{})
+	t := time.AfterFunc(timeout, func() { close(expired) })
+	return expired, func() { t.Stop() }
+}
+
+// dispatchParallel sends an event, or a break if brk is set, to the Handlers of
+// all of ws at once, and waits up to timeout for all of them to finish. The
+// combined error of all Handlers is returned, including an error wrapping
+// ErrWriteTimeout for each one that did not finish in time.
+func dispatchParallel[E any](ws []*dispatchWorker[E], timeout time.Duration, calldepth int, evt Event[E], brk bool) error {
+	expired, stop := deadline(timeout)
+	defer stop()
+
+	var fullErr error
+	dones := make([]chan error, len(ws))
+	for i := range ws {
+		done, err := ws[i].enqueue(calldepth, evt, brk, expired)
+		if err != nil {
+			fullErr = joinHandlerErr(fullErr, err)
+			continue
+		}
+		dones[i] = done
+	}
+	for i := range dones {
+		if dones[i] != nil {
+			fullErr = joinHandlerErr(fullErr, wait(dones[i], expired, timeout))
+		}
+	}
+
+	return fullErr
+}
+
+// WriteTimeoutHandler is a Handler that wraps another Handler and limits how
+// long a call to Output or InsertBreak can block. The wrapped Handler is called
+// from a separate goroutine, one event at a time and in the order they were
+// received; if it does not finish before the timeout elapses, an error
+// wrapping ErrWriteTimeout is returned to the caller right away and the
+// wrapped Handler is left to finish on its own.
+//
+// This keeps a destination that blocks, such as a network connection to an
+// unresponsive host or a pipe that no one is reading, from hanging the
+// program. Events logged while the wrapped Handler is blocked are queued, up to
+// a limit; once the queue is full, further events are dropped when their
+// timeout elapses.
+//
+// A WriteTimeoutHandler is safe to use from multiple goroutines. It should be
+// created with NewWriteTimeoutHandler.
+type WriteTimeoutHandler[E any] struct {
+	w       *dispatchWorker[E]
+	timeout time.Duration
+}
+
+// NewWriteTimeoutHandler creates a WriteTimeoutHandler that passes events
+// through to h, giving it up to timeout to finish with each one. If timeout is
+// not positive, calls wait for h to finish no matter how long it takes.
+func NewWriteTimeoutHandler[E any](h Handler[E], timeout time.Duration) *WriteTimeoutHandler[E] {
+	return &WriteTimeoutHandler[E]{
+		w:       newDispatchWorker(h),
+		timeout: timeout,
+	}
+}
+
+// HandlerOptions returns the options of the Handler that wth wraps.
+func (wth *WriteTimeoutHandler[E]) HandlerOptions() HandlerOptions[E] {
+	return wth.w.h.HandlerOptions()
+}
+
+// InsertBreak inserts a break in the Handler that wth wraps, waiting no longer
+// than the configured timeout.
+func (wth *WriteTimeoutHandler[E]) InsertBreak() error {
+	return wth.do(0, Event[E]{}, true)
+}
+
+// Output passes a log event through to the wrapped Handler, waiting no longer
+// than the configured timeout for it to finish.
+//
+// The calldepth argument is used for recovering the program counter. It should
+// be supplied with the number of levels into the jellog package that the caller
+// has reached, with the externally called function counting as 1.
+func (wth *WriteTimeoutHandler[E]) Output(calldepth int, evt Event[E]) error {
+	// the wrapped Handler runs on a different goroutine, so it must be given
+	// the PC and stack rather than finding them itself
+	if evt.PC == 0 {
+		evt.PC = callerPC(calldepth)
+	}
+	if evt.Stack == nil && evt.callers == nil {
+		evt.callers = callerPCs(calldepth)
+	}
+
+	return wth.do(calldepth+1, evt, false)
+}
+
+func (wth *WriteTimeoutHandler[E]) do(calldepth int, evt Event[E], brk bool) error {
+	expired, stop := deadline(wth.timeout)
+	defer stop()
+
+	done, err := wth.w.enqueue(calldepth, evt, brk, expired)
+	if err != nil {
+		return err
+	}
+	return wait(done, expired, wth.timeout)
+}
+
+// WrappedHandlers returns the Handler that wth wraps.
+func (wth *WriteTimeoutHandler[E]) WrappedHandlers() []Handler[E] {
+	return []Handler[E]{wth.w.h}
+}
+
+// Flush waits for all events queued for the wrapped Handler to be passed to
+// it, or for ctx to be done. It does not flush the wrapped Handler itself; use
+// FlushHandlers or Logger.Flush for that.
+func (wth *WriteTimeoutHandler[E]) Flush(ctx context.Context) error {
+	return wth.w.drain(ctx, false)
+}
+
+// Close waits for all events queued for the wrapped Handler to be passed to
+// it, or for ctx to be done, and then stops the goroutine that calls it. The
+// goroutine is started again if wth is used after it is closed. It
+// does not close the wrapped Handler itself; use CloseHandlers or Logger.Close
+// for that.
+func (wth *WriteTimeoutHandler[E]) Close(ctx context.Context) error {
+	return wth.w.drain(ctx, true)
+}
@@ -0,0 +1,43 @@
+package jellog
+
+import (
+	"testing"
+	"time"
+)
+
+// nopHandler is a Handler that discards everything it is given.
+type nopHandler struct{}
+
+func (nopHandler) HandlerOptions() HandlerOptions[string]        { return HandlerOptions[string]{} }
+func (nopHandler) Output(calldepth int, evt Event[string]) error { return nil }
+func (nopHandler) InsertBreak() error                            { return nil }
+
+func Test_dispatchWorker_idleExit(t *testing.T) {
+	w := newDispatchWorker[string](nopHandler{})
+	w.idle = 10 * time.Millisecond
+	running := func() bool {
+		w.mtx.Lock()
+		defer w.mtx.Unlock()
+		return w.running
+	}
+
+	for i := 0; i < 2; i++ {
+		done, err := w.enqueue(1, Event[string]{Message: "hello"}, false, nil)
+		if err != nil {
+			t.Fatalf("round %d: enqueue: %v", i, err)
+		}
+		if err := <-done; err != nil {
+			t.Fatalf("round %d: output: %v", i, err)
+		}
+
+		// the goroutine exits on its own once idle, and is started again by
+		// the next round
+		deadline := time.Now().Add(5 * time.Second)
+		for running() {
+			if time.Now().After(deadline) {
+				t.Fatalf("round %d: worker still running after being idle", i)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+}
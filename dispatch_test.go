@@ -0,0 +1,477 @@
+package jellog_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dekarrin/jellog"
+	"github.com/dekarrin/jellog/jellogtest"
+)
+
+// failingHandler records events like a Recorder, except that events matched by
+// fail are not recorded and err is returned for them instead.
+type failingHandler struct {
+	*jellogtest.Recorder[string]
+	fail jellogtest.Matcher[string]
+	err  error
+}
+
+func (fh *failingHandler) Output(calldepth int, evt jellog.Event[string]) error {
+	if fh.fail(evt) {
+		return fh.err
+	}
+	return fh.Recorder.Output(calldepth+1, evt)
+}
+
+// blockingHandler records events like a Recorder, but does not return from
+// Output or InsertBreak until unblock is called.
+type blockingHandler struct {
+	*jellogtest.Recorder[string]
+	release chan struct{}
+	once    sync.Once
+}
+
+func newBlockingHandler() *blockingHandler {
+	return &blockingHandler{
+		Recorder: jellogtest.NewRecorder[string](nil),
+		release:  make(chan struct{}),
+	}
+}
+
+func (bh *blockingHandler) Output(calldepth int, evt jellog.Event[string]) error {
+	<-bh.release
+	return bh.Recorder.Output(calldepth+1, evt)
+}
+
+func (bh *blockingHandler) InsertBreak() error {
+	<-bh.release
+	return bh.Recorder.InsertBreak()
+}
+
+func (bh *blockingHandler) unblock() {
+	bh.once.Do(func() { close(bh.release) })
+}
+
+// waitFor fails the test if cond does not become true within a few seconds.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// overlapHandler records events like a Recorder, and records whether Output
+// was ever called while another call to it was still running.
+type overlapHandler struct {
+	*jellogtest.Recorder[string]
+	running  int32
+	overlaps int32
+}
+
+func (oh *overlapHandler) Output(calldepth int, evt jellog.Event[string]) error {
+	if atomic.AddInt32(&oh.running, 1) > 1 {
+		atomic.StoreInt32(&oh.overlaps, 1)
+	}
+	defer atomic.AddInt32(&oh.running, -1)
+
+	time.Sleep(100 * time.Microsecond)
+	return oh.Recorder.Output(calldepth+1, evt)
+}
+
+func messages(evts []jellog.Event[string]) []string {
+	msgs := make([]string, len(evts))
+	for i := range evts {
+		msgs[i] = evts[i].Message
+	}
+	return msgs
+}
+
+func Test_WriteTimeoutHandler_Output(t *testing.T) {
+	testCases := []struct {
+		name          string
+		timeout       time.Duration
+		block         bool
+		expectTimeout bool
+	}{
+		{name: "fast handler", timeout: time.Second},
+		{name: "no timeout", timeout: 0},
+		{name: "blocked handler", timeout: 10 * time.Millisecond, block: true, expectTimeout: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			bh := newBlockingHandler()
+			if !tc.block {
+				bh.unblock()
+			}
+			defer bh.unblock()
+			wth := jellog.NewWriteTimeoutHandler[string](bh, tc.timeout)
+			defer wth.Close(context.Background())
+
+			var errs []error
+			for i := 0; i < 3; i++ {
+				evt := jellog.Event[string]{Level: jellog.LvInfo, Message: fmt.Sprintf("event %d", i)}
+				errs = append(errs, wth.Output(1, evt))
+			}
+			errs = append(errs, wth.InsertBreak())
+
+			for i, err := range errs {
+				if tc.expectTimeout && !errors.Is(err, jellog.ErrWriteTimeout) {
+					t.Errorf("call %d: expected ErrWriteTimeout, got %v", i, err)
+				}
+				if !tc.expectTimeout && err != nil {
+					t.Errorf("call %d: unexpected error: %v", i, err)
+				}
+			}
+
+			// events that timed out are still passed on once the handler
+			// stops blocking, and in order
+			bh.unblock()
+			if err := wth.Flush(context.Background()); err != nil {
+				t.Fatalf("Flush: %v", err)
+			}
+			expect := []string{"event 0", "event 1", "event 2"}
+			if actual := messages(bh.Events()); strings.Join(actual, ",") != strings.Join(expect, ",") {
+				t.Errorf("expected events %q, got %q", expect, actual)
+			}
+			if bh.Breaks() != 1 {
+				t.Errorf("expected 1 break, got %d", bh.Breaks())
+			}
+		})
+	}
+}
+
+func Test_WriteTimeoutHandler_Output_queueFull(t *testing.T) {
+	bh := newBlockingHandler()
+	defer bh.unblock()
+	wth := jellog.NewWriteTimeoutHandler[string](bh, time.Millisecond)
+
+	// one event is taken by the blocked handler and the rest wait in the
+	// queue until it is full, after which events are dropped
+	dropped := -1
+	for i := 0; i < 300 && dropped < 0; i++ {
+		err := wth.Output(1, jellog.Event[string]{Level: jellog.LvInfo, Message: fmt.Sprint(i)})
+		if !errors.Is(err, jellog.ErrWriteTimeout) {
+			t.Fatalf("event %d: expected ErrWriteTimeout, got %v", i, err)
+		}
+		if strings.Contains(err.Error(), "dropped") {
+			dropped = i
+		}
+	}
+	if dropped < 0 {
+		t.Fatalf("expected an event to be dropped once the queue was full")
+	}
+
+	bh.unblock()
+	if err := wth.Close(context.Background()); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	evts := bh.Events()
+	if len(evts) != dropped {
+		t.Fatalf("expected the %d events before the dropped one to be output, got %d", dropped, len(evts))
+	}
+	for i, evt := range evts {
+		if evt.Message != fmt.Sprint(i) {
+			t.Fatalf("event %d: expected message %q, got %q", i, fmt.Sprint(i), evt.Message)
+		}
+	}
+}
+
+func Test_WriteTimeoutHandler_Flush_ctxDone(t *testing.T) {
+	bh := newBlockingHandler()
+	defer bh.unblock()
+	wth := jellog.NewWriteTimeoutHandler[string](bh, time.Millisecond)
+
+	wth.Output(1, jellog.Event[string]{Level: jellog.LvInfo, Message: "stuck"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := wth.Flush(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func Test_Logger_parallelDispatch(t *testing.T) {
+	errA := errors.New("handler a failed")
+	errB := errors.New("handler b failed")
+
+	testCases := []struct {
+		name      string
+		timeout   time.Duration
+		handlers  func() []jellog.Handler[string]
+		expectErr []error
+	}{
+		{
+			name: "all handlers succeed",
+			handlers: func() []jellog.Handler[string] {
+				return []jellog.Handler[string]{jellogtest.NewRecorder[string](nil), jellogtest.NewRecorder[string](nil)}
+			},
+		},
+		{
+			name:    "slow handler times out",
+			timeout: 20 * time.Millisecond,
+			handlers: func() []jellog.Handler[string] {
+				return []jellog.Handler[string]{jellogtest.NewRecorder[string](nil), newBlockingHandler()}
+			},
+			expectErr: []error{jellog.ErrWriteTimeout},
+		},
+		{
+			name: "errors from every handler are returned",
+			handlers: func() []jellog.Handler[string] {
+				all := jellogtest.All[string]()
+				return []jellog.Handler[string]{
+					&failingHandler{Recorder: jellogtest.NewRecorder[string](nil), fail: all, err: errA},
+					jellogtest.NewRecorder[string](nil),
+					&failingHandler{Recorder: jellogtest.NewRecorder[string](nil), fail: all, err: errB},
+				}
+			},
+			expectErr: []error{errA, errB},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			hs := tc.handlers()
+			lg := jellog.New(jellog.Defaults[string]().WithParallelDispatch(true, tc.timeout))
+			for _, h := range hs {
+				lg.AddHandler(jellog.LvTrace, h)
+			}
+			defer func() {
+				for _, h := range hs {
+					if bh, ok := h.(*blockingHandler); ok {
+						bh.unblock()
+					}
+				}
+				lg.Close(context.Background())
+			}()
+
+			err := lg.Output(1, lg.CreateEvent(jellog.LvInfo, "hello"))
+
+			if len(tc.expectErr) == 0 && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			for _, expect := range tc.expectErr {
+				if !errors.Is(err, expect) {
+					t.Errorf("expected error to wrap %v, got %v", expect, err)
+				}
+			}
+
+			// handlers that finished in time have the event as soon as Output
+			// returns
+			for i, h := range hs {
+				if rec, ok := h.(*jellogtest.Recorder[string]); ok && !rec.HasEvent(jellog.LvInfo, "hello") {
+					t.Errorf("handler %d did not receive the event", i)
+				}
+			}
+		})
+	}
+}
+
+func Test_Logger_parallelDispatch_order(t *testing.T) {
+	recA := jellogtest.NewRecorder[string](nil)
+	recB := jellogtest.NewRecorder[string](nil)
+	lg := jellog.New(jellog.Defaults[string]().WithParallelDispatch(true, 0))
+	lg.AddHandler(jellog.LvTrace, recA)
+	lg.AddHandler(jellog.LvWarn, recB)
+	defer lg.Close(context.Background())
+
+	for i := 0; i < 100; i++ {
+		lg.Info(fmt.Sprint(i))
+		lg.Warn(fmt.Sprint(i))
+	}
+
+	if recA.Len() != 200 {
+		t.Errorf("expected 200 events for TRACE handler, got %d", recA.Len())
+	}
+	if n := recA.CountLevel(jellog.LvInfo); n != 100 {
+		t.Errorf("expected 100 INFO events for TRACE handler, got %d", n)
+	}
+	if recB.Len() != 100 || recB.CountLevel(jellog.LvWarn) != 100 {
+		t.Errorf("expected 100 WARN events only for WARN handler, got %d events", recB.Len())
+	}
+	for i, evt := range recB.Events() {
+		if evt.Message != fmt.Sprint(i) {
+			t.Fatalf("WARN handler event %d: expected message %q, got %q", i, fmt.Sprint(i), evt.Message)
+		}
+	}
+}
+
+func Test_Logger_parallelDispatch_errorHandler(t *testing.T) {
+	var mtx sync.Mutex
+	var reported []error
+
+	bh := newBlockingHandler()
+	opts := jellog.Defaults[string]().
+		WithParallelDispatch(true, 10*time.Millisecond).
+		WithErrorHandler(func(err error) {
+			mtx.Lock()
+			defer mtx.Unlock()
+			reported = append(reported, err)
+		})
+	lg := jellog.New(opts)
+	lg.AddHandler(jellog.LvTrace, bh)
+
+	lg.Info("one")
+	lg.Info("two")
+
+	mtx.Lock()
+	if len(reported) != 2 {
+		t.Errorf("expected 2 errors to be reported, got %d", len(reported))
+	}
+	for _, err := range reported {
+		if !errors.Is(err, jellog.ErrWriteTimeout) {
+			t.Errorf("expected reported error to wrap ErrWriteTimeout, got %v", err)
+		}
+	}
+	mtx.Unlock()
+
+	// the events are still delivered once the handler recovers, and Close
+	// waits for them
+	bh.unblock()
+	if err := lg.Close(context.Background()); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if actual := messages(bh.Events()); strings.Join(actual, ",") != "one,two" {
+		t.Errorf("expected events [one two], got %q", actual)
+	}
+}
+
+func Test_Logger_Close_stopsDispatchGoroutines(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	lg := jellog.New(jellog.Defaults[string]().WithParallelDispatch(true, time.Second))
+	for i := 0; i < 5; i++ {
+		lg.AddHandler(jellog.LvTrace, jellogtest.NewRecorder[string](nil))
+	}
+	lg.Info("start the workers")
+
+	if err := lg.Close(context.Background()); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	waitFor(t, "dispatch goroutines to exit", func() bool {
+		return runtime.NumGoroutine() <= before
+	})
+}
+
+func Test_Logger_parallelDispatch_sharedHandler(t *testing.T) {
+	testCases := []struct {
+		name    string
+		loggers func(h jellog.Handler[string]) []jellog.Logger[string]
+	}{
+		{
+			name: "added twice",
+			loggers: func(h jellog.Handler[string]) []jellog.Logger[string] {
+				lg := jellog.New(jellog.Defaults[string]().WithParallelDispatch(true, 0))
+				lg.AddHandler(jellog.LvTrace, h)
+				lg.AddHandler(jellog.LvInfo, h)
+				return []jellog.Logger[string]{lg}
+			},
+		},
+		{
+			name: "shared with copies",
+			loggers: func(h jellog.Handler[string]) []jellog.Logger[string] {
+				lg := jellog.New(jellog.Defaults[string]().WithParallelDispatch(true, 0))
+				lg.AddHandler(jellog.LvTrace, h)
+				return []jellog.Logger[string]{
+					lg,
+					lg.Copy(jellog.Options[string]{}.WithComponent("a")),
+					lg.Copy(jellog.Options[string]{}.WithComponent("b")),
+				}
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			oh := &overlapHandler{Recorder: jellogtest.NewRecorder[string](nil)}
+			lgs := tc.loggers(oh)
+			defer lgs[0].Close(context.Background())
+
+			var wg sync.WaitGroup
+			for _, lg := range lgs {
+				for g := 0; g < 3; g++ {
+					wg.Add(1)
+					go func(lg jellog.Logger[string]) {
+						defer wg.Done()
+						for i := 0; i < 20; i++ {
+							lg.Info(fmt.Sprint(i))
+						}
+					}(lg)
+				}
+			}
+			wg.Wait()
+
+			if atomic.LoadInt32(&oh.overlaps) != 0 {
+				t.Errorf("expected handler to be called one event at a time, but calls overlapped")
+			}
+			if oh.Len() == 0 {
+				t.Errorf("expected handler to receive events")
+			}
+		})
+	}
+}
+
+func Test_Logger_parallelDispatch_stack(t *testing.T) {
+	testCases := []struct {
+		name string
+		wrap func(h jellog.Handler[string]) jellog.Logger[string]
+	}{
+		{
+			name: "parallel dispatch",
+			wrap: func(h jellog.Handler[string]) jellog.Logger[string] {
+				lg := jellog.New(jellog.Defaults[string]().WithParallelDispatch(true, 0))
+				lg.AddHandler(jellog.LvTrace, h)
+				return lg
+			},
+		},
+		{
+			name: "WriteTimeoutHandler",
+			wrap: func(h jellog.Handler[string]) jellog.Logger[string] {
+				lg := jellog.New(jellog.Defaults[string]())
+				lg.AddHandler(jellog.LvTrace, jellog.NewWriteTimeoutHandler(h, 0))
+				return lg
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := jellogtest.NewRecorder[string](nil)
+			inner := jellog.New(jellog.Defaults[string]().WithStackTraceLevel(jellog.LvError))
+			inner.AddHandler(jellog.LvTrace, rec)
+			lg := tc.wrap(inner.AsHandler())
+			defer lg.Close(context.Background())
+
+			lg.Error("failed")
+
+			evts := rec.Events()
+			if len(evts) != 1 {
+				t.Fatalf("expected 1 event, got %d", len(evts))
+			}
+			stack := evts[0].Stack
+			if len(stack) < 1 {
+				t.Fatalf("expected event to have a stack trace")
+			}
+			if !strings.Contains(stack[0].Function, ".Test_Logger_parallelDispatch_stack.") || !strings.HasSuffix(stack[0].File, "dispatch_test.go") {
+				t.Errorf("expected stack to start at the logging call, got %s", stack[0])
+			}
+			for _, f := range stack {
+				if strings.Contains(f.Function, "dispatchWorker") {
+					t.Errorf("expected stack of the logging goroutine, got frame %s", f)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+package jellog
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// maxCauseDepth is how many levels of wrapping are followed when an
+	// error's causes are collected. Anything deeper is left out.
+	maxCauseDepth = 32
+
+	// maxCauses is the most causes that are collected for a single error.
+	maxCauses = 100
+)
+
+// Cause is a single error in the chain of an error logged with one of the error
+// logging methods, such as Logger.Err. It has JSON tags so that structured
+// Formatters can emit the chain directly as an array of objects.
+type Cause struct {
+	// Message is the result of calling Error() on the error.
+	Message string `json:"message"`
+
+	// Type is the Go type of the error, such as "*fs.PathError".
+	Type string `json:"type"`
+
+	// Detail is the error formatted with %+v, if it implements fmt.Formatter
+	// and that gives something other than Message. Errors from packages that
+	// record stack traces often provide those this way.
+	Detail string `json:"detail,omitempty"`
+
+	// Depth is how many levels of wrapping the error is below the logged
+	// error. The logged error itself has a Depth of 0, errors that it wraps
+	// have a Depth of 1, errors that those wrap have a Depth of 2, and so on.
+	Depth int `json:"depth"`
+}
+
+// errorCauses returns the chain of err as a list of Causes, starting with err
+// itself. Wrapped errors are found in the same way as errors.Is does, so both
+// errors that wrap a single error and errors that wrap several, such as those
+// made by errors.Join, are followed; the list is in depth-first order, with
+// each error followed by the errors that it wraps.
+func errorCauses(err error) []Cause {
+	var causes []Cause
+
+	var walk func(err error, depth int)
+	walk = func(err error, depth int) {
+		if err == nil || depth > maxCauseDepth || len(causes) >= maxCauses {
+			return
+		}
+
+		c := Cause{Message: err.Error(), Type: fmt.Sprintf("%T", err), Depth: depth}
+		if _, ok := err.(fmt.Formatter); ok {
+			if detail := fmt.Sprintf("%+v", err); detail != c.Message {
+				c.Detail = detail
+			}
+		}
+		causes = append(causes, c)
+
+		switch wrapper := err.(type) {
+		case interface{ Unwrap() []error }:
+			for _, wrapped := range wrapper.Unwrap() {
+				walk(wrapped, depth+1)
+			}
+		case interface{ Unwrap() error }:
+			walk(wrapper.Unwrap(), depth+1)
+		}
+	}
+	walk(err, 0)
+
+	return causes
+}
+
+// appendCauses appends causes to buf as indented lines, with each wrapped error
+// indented one more level than the error that wraps it:
+//
+//	caused by: open config.yml: permission denied [*fs.PathError]
+//		caused by: permission denied [syscall.Errno]
+//
+// The logged error itself is already part of the message, so it is only
+// written if it has a Detail. Because the %+v form of an error conventionally
+// includes that of the errors it wraps, the errors below a cause with a Detail
+// are not written.
+//
+// Error messages often contain data from outside the program, so control
+// characters in them other than tabs are escaped in the same way as they are
+// for messages written with MessageIndent.
+func appendCauses(buf []byte, causes []Cause) []byte {
+	skipBelow := -1
+	for _, c := range causes {
+		if skipBelow >= 0 {
+			if c.Depth > skipBelow {
+				continue
+			}
+			skipBelow = -1
+		}
+
+		text := c.Detail
+		if text != "" {
+			skipBelow = c.Depth
+		} else if c.Depth == 0 {
+			continue
+		} else {
+			text = c.Message
+		}
+
+		indent := "\t"
+		if c.Depth > 1 {
+			indent = strings.Repeat("\t", c.Depth)
+		}
+		lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+
+		buf = append(buf, indent...)
+		if c.Depth == 0 {
+			buf = append(buf, "error: "...)
+		} else {
+			buf = append(buf, "caused by: "...)
+		}
+		buf = appendCauseLine(buf, lines[0])
+		buf = append(buf, " ["...)
+		buf = append(buf, c.Type...)
+		buf = append(buf, "]\n"...)
+
+		// continuation lines are indented further so that they cannot be
+		// mistaken for the start of a new log entry
+		for _, line := range lines[1:] {
+			buf = append(buf, indent...)
+			buf = append(buf, '\t')
+			buf = appendCauseLine(buf, line)
+			buf = append(buf, '\n')
+		}
+	}
+	return buf
+}
+
+// appendCauseLine appends a single line of the text of a Cause to buf, with
+// control characters other than tabs escaped.
+func appendCauseLine(buf []byte, line string) []byte {
+	if !needsEscape(line, true) {
+		return append(buf, line...)
+	}
+	return appendEscaped(buf, line, "\n")
+}
+
+// Err logs an error at severity level ERROR. The error is recorded in the
+// Event's Err field and the chain of errors that it wraps in its Causes, so
+// that Formatters can show the full chain. Supplementary information is
+// gathered along with the message into an Event which is then passed to the
+// appropriate Handlers.
+//
+// The message is created from msg in the manner of fmt.Print, followed by ": "
+// and the error's own message. If msg is empty, the message is only that of
+// the error. It is then converted to the type of logged object handled by the
+// Logger by using the Logger's Converter function. Because error messages
+// often contain data from outside the program, the Event is always marked as
+// Converted so that Formatters treat its message as untrusted.
+func (lg Logger[E]) Err(err error, msg ...any) {
+	lg.logErr(2, LvError, err, fmt.Sprint(msg...))
+}
+
+// Errf logs an error at severity level ERROR as Err does, but with the message
+// created as a formatted string.
+func (lg Logger[E]) Errf(err error, format string, a ...interface{}) {
+	lg.logErr(2, LvError, err, fmt.Sprintf(format, a...))
+}
+
+// LogErr logs an error at the given severity level as Err does.
+func (lg Logger[E]) LogErr(lv Level, err error, msg ...any) {
+	lg.logErr(2, lv, err, fmt.Sprint(msg...))
+}
+
+// FatalErr logs an error at severity level FATAL as Err does and then exits
+// the program with status code 1 as described in Exit.
+func (lg Logger[E]) FatalErr(err error, msg ...any) {
+	lg.logErr(2, LvFatal, err, fmt.Sprint(msg...))
+	lg.Exit(1)
+}
+
+// logErr creates an Event for err with msg as errEvent does and outputs it.
+// The calldepth argument is the same as for log.
+func (lg Logger[E]) logErr(calldepth int, lv Level, err error, msg string) {
+	evt := lg.errEvent(lv, err, msg)
+	lg.HandleError(lg.Output(calldepth+1, evt))
+}
+
+// errEvent creates an Event for err with msg, recording err and the chain of
+// errors it wraps in the Event.
+func (lg Logger[E]) errEvent(lv Level, err error, msg string) Event[E] {
+	text := fmt.Sprint(err)
+	if msg != "" {
+		text = msg + ": " + text
+	}
+
+	evt := lg.CreateEvent(lv, text)
+
+	// error messages often carry data from outside the program and may span
+	// several lines, as those made by errors.Join do, so give Formatters the
+	// same chance to guard against them as they have for converted messages
+	evt.Converted = true
+	evt.Err = err
+	evt.Causes = errorCauses(err)
+	return evt
+}
+
+// Err logs an error with severity level ERROR using the default logger. The
+// error and the chain of errors it wraps are recorded in the Event as
+// described in Logger.Err. Arguments are handled in the manner of fmt.Print.
+func Err(err error, msg ...any) {
+	std.logErr(2, LvError, err, fmt.Sprint(msg...))
+}
+
+// Errf logs an error with severity level ERROR using the default logger, as
+// Err does, but with the message created as a formatted string.
+func Errf(err error, format string, a ...interface{}) {
+	std.logErr(2, LvError, err, fmt.Sprintf(format, a...))
+}
+
+// LogErr logs an error with the given severity level using the default logger,
+// as Err does.
+func LogErr(lv Level, err error, msg ...any) {
+	std.logErr(2, lv, err, fmt.Sprint(msg...))
+}
+
+// FatalErr logs an error with severity level FATAL using the default logger,
+// as Err does, and then exits the program with status code 1 as described in
+// Logger.Exit.
+func FatalErr(err error, msg ...any) {
+	std.logErr(2, LvFatal, err, fmt.Sprint(msg...))
+	std.Exit(1)
+}
@@ -0,0 +1,265 @@
+package jellog_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dekarrin/jellog"
+	"github.com/dekarrin/jellog/jellogtest"
+)
+
+func Test_Logger_Err_causes(t *testing.T) {
+	forged := "\x1b[31mboom\r2026/01/01 00:00:00 INFO forged"
+	secret := "user bob@example.com sent Bearer abc.def.ghi"
+
+	testCases := []struct {
+		name      string
+		err       error
+		redact    bool
+		format    jellog.Formatter[string]
+		expect    []string
+		notExpect []string
+	}{
+		{
+			name:   "wrapped errors are listed",
+			err:    fmt.Errorf("load config: %w", &os.PathError{Op: "open", Path: "config.yml", Err: os.ErrPermission}),
+			format: jellog.LineFormat{},
+			expect: []string{
+				"\tcaused by: open config.yml: permission denied [*fs.PathError]\n",
+				"\t\tcaused by: permission denied [*errors.errorString]\n",
+			},
+		},
+		{
+			name:      "control characters in causes are escaped",
+			err:       fmt.Errorf("outer: %w", errors.New(forged)),
+			format:    jellog.LineFormat{},
+			expect:    []string{`caused by: \x1b[31mboom\r2026/01/01 00:00:00 INFO forged [*errors.errorString]`},
+			notExpect: []string{"\x1b", "\r"},
+		},
+		{
+			name:      "multi-line causes cannot start a new entry",
+			err:       fmt.Errorf("outer: %w", errors.New("first\n2026/01/01 00:00:00 INFO forged")),
+			format:    jellog.LineFormat{},
+			expect:    []string{"\tcaused by: first [*errors.errorString]\n\t\t2026/01/01 00:00:00 INFO forged\n"},
+			notExpect: []string{"\n2026"},
+		},
+		{
+			name:      "error directive is escaped",
+			err:       errors.New(forged),
+			format:    jellog.MustPatternFormat("%{error}"),
+			expect:    []string{`\x1b[31mboom\r2026/01/01 00:00:00 INFO forged`},
+			notExpect: []string{"\x1b", "\r"},
+		},
+		{
+			name:      "causes are redacted",
+			err:       fmt.Errorf("outer: %w", errors.New(secret)),
+			redact:    true,
+			format:    jellog.LineFormat{},
+			expect:    []string{"caused by: user " + jellog.RedactedMask},
+			notExpect: []string{"bob@example.com", "abc.def.ghi"},
+		},
+		{
+			name:      "error directive is redacted",
+			err:       fmt.Errorf("outer: %w", errors.New(secret)),
+			redact:    true,
+			format:    jellog.MustPatternFormat("%{error}"),
+			expect:    []string{"outer: user " + jellog.RedactedMask},
+			notExpect: []string{"bob@example.com", "abc.def.ghi"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := jellog.Defaults[string]()
+			if tc.redact {
+				r := jellog.NewRedactor(jellog.DefaultRedactRules(), jellog.DefaultRedactKeys())
+				opts = opts.WithProcessor(jellog.RedactProcessor[string](r))
+			}
+			rec := jellogtest.NewRecorder[string](nil)
+			lg := jellog.New(opts)
+			lg.AddHandler(jellog.LvTrace, rec)
+
+			lg.Err(tc.err, "failed")
+
+			evts := rec.Filter(jellogtest.HasError[string](tc.err))
+			if len(evts) != 1 {
+				t.Fatalf("expected 1 event for the error, got %d", len(evts))
+			}
+			out := string(tc.format.Format(evts[0]))
+
+			for _, s := range tc.expect {
+				if !strings.Contains(out, s) {
+					t.Errorf("expected output to contain %q, got:\n%s", s, out)
+				}
+			}
+			for _, s := range tc.notExpect {
+				if strings.Contains(out, s) {
+					t.Errorf("expected output not to contain %q, got:\n%s", s, out)
+				}
+			}
+		})
+	}
+}
+
+// joinedError wraps several errors in the same way as those made by
+// errors.Join, which is not available in the version of Go the module targets.
+type joinedError []error
+
+func (je joinedError) Error() string {
+	msgs := make([]string, len(je))
+	for i := range je {
+		msgs[i] = je[i].Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func (je joinedError) Unwrap() []error {
+	return je
+}
+
+// detailedError gives extra detail when formatted with %+v, as errors from
+// packages that record stack traces do.
+type detailedError struct {
+	msg    string
+	detail string
+	err    error
+}
+
+func (de *detailedError) Error() string {
+	return de.msg
+}
+
+func (de *detailedError) Unwrap() error {
+	return de.err
+}
+
+func (de *detailedError) Format(f fmt.State, verb rune) {
+	if verb == 'v' && f.Flag('+') && de.detail != "" {
+		fmt.Fprint(f, de.msg+"\n"+de.detail)
+		return
+	}
+	fmt.Fprint(f, de.msg)
+}
+
+func Test_Logger_Err_causeList(t *testing.T) {
+	errA := errors.New("a failed")
+	errB := errors.New("b failed")
+
+	testCases := []struct {
+		name   string
+		err    error
+		expect []jellog.Cause
+	}{
+		{
+			name: "single error",
+			err:  errA,
+			expect: []jellog.Cause{
+				{Message: "a failed", Type: "*errors.errorString", Depth: 0},
+			},
+		},
+		{
+			name: "joined errors are listed depth-first",
+			err:  fmt.Errorf("outer: %w", joinedError{fmt.Errorf("wrapped: %w", errA), errB}),
+			expect: []jellog.Cause{
+				{Message: "outer: wrapped: a failed\nb failed", Type: "*fmt.wrapError", Depth: 0},
+				{Message: "wrapped: a failed\nb failed", Type: "jellog_test.joinedError", Depth: 1},
+				{Message: "wrapped: a failed", Type: "*fmt.wrapError", Depth: 2},
+				{Message: "a failed", Type: "*errors.errorString", Depth: 3},
+				{Message: "b failed", Type: "*errors.errorString", Depth: 2},
+			},
+		},
+		{
+			name: "detail is recorded",
+			err:  fmt.Errorf("outer: %w", &detailedError{msg: "inner", detail: "at main.go:42", err: errA}),
+			expect: []jellog.Cause{
+				{Message: "outer: inner", Type: "*fmt.wrapError", Depth: 0},
+				{Message: "inner", Type: "*jellog_test.detailedError", Detail: "inner\nat main.go:42", Depth: 1},
+				{Message: "a failed", Type: "*errors.errorString", Depth: 2},
+			},
+		},
+		{
+			name: "formatter without extra detail",
+			err:  &detailedError{msg: "inner"},
+			expect: []jellog.Cause{
+				{Message: "inner", Type: "*jellog_test.detailedError", Depth: 0},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := jellogtest.NewRecorder[string](nil)
+			lg := jellog.New(jellog.Defaults[string]())
+			lg.AddHandler(jellog.LvTrace, rec)
+
+			lg.Err(tc.err)
+
+			evts := rec.Events()
+			if len(evts) != 1 {
+				t.Fatalf("expected 1 event, got %d", len(evts))
+			}
+			if !reflect.DeepEqual(evts[0].Causes, tc.expect) {
+				t.Errorf("expected causes:\n%+v\ngot:\n%+v", tc.expect, evts[0].Causes)
+			}
+		})
+	}
+}
+
+func Test_LineFormat_Format_causes(t *testing.T) {
+	testCases := []struct {
+		name   string
+		causes []jellog.Cause
+		expect string
+	}{
+		{
+			name: "joined errors",
+			causes: []jellog.Cause{
+				{Message: "a failed\nb failed", Type: "jellog_test.joinedError", Depth: 0},
+				{Message: "a failed", Type: "*errors.errorString", Depth: 1},
+				{Message: "b failed", Type: "*errors.errorString", Depth: 1},
+			},
+			expect: "ERROR failed\n" +
+				"\tcaused by: a failed [*errors.errorString]\n" +
+				"\tcaused by: b failed [*errors.errorString]\n",
+		},
+		{
+			name: "detail replaces the causes below it",
+			causes: []jellog.Cause{
+				{Message: "outer: inner", Type: "*fmt.wrapError", Depth: 0},
+				{Message: "inner", Type: "*jellog_test.detailedError", Detail: "inner\nat main.go:42", Depth: 1},
+				{Message: "a failed", Type: "*errors.errorString", Depth: 2},
+				{Message: "b failed", Type: "*errors.errorString", Depth: 1},
+			},
+			expect: "ERROR failed\n" +
+				"\tcaused by: inner [*jellog_test.detailedError]\n" +
+				"\t\tat main.go:42\n" +
+				"\tcaused by: b failed [*errors.errorString]\n",
+		},
+		{
+			name: "detail of the logged error",
+			causes: []jellog.Cause{
+				{Message: "inner", Type: "*jellog_test.detailedError", Detail: "inner\nat main.go:42", Depth: 0},
+				{Message: "a failed", Type: "*errors.errorString", Depth: 1},
+			},
+			expect: "ERROR failed\n" +
+				"\terror: inner [*jellog_test.detailedError]\n" +
+				"\t\tat main.go:42\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			evt := jellog.Event[string]{Level: jellog.LvError, Message: "failed", Causes: tc.causes}
+
+			actual := string(jellog.LineFormat{OmitTime: true}.Format(evt))
+
+			if actual != tc.expect {
+				t.Errorf("expected %q, got %q", tc.expect, actual)
+			}
+		})
+	}
+}
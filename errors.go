@@ -0,0 +1,339 @@
+package jellog
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// errorReportInterval is the minimum amount of time between two reports to
+// stderr of Handler errors by a Logger without an ErrorHandler.
+const errorReportInterval = 10 * time.Second
+
+// errorState tracks the Handler errors of a Logger. It is shared between all
+// copies of the Logger value.
+type errorState struct {
+	mtx        sync.Mutex
+	last       error
+	lastReport time.Time
+	suppressed int
+}
+
+// record saves err as the most recent error if it is non-nil.
+func (es *errorState) record(err error) {
+	if err == nil {
+		return
+	}
+
+	es.mtx.Lock()
+	defer es.mtx.Unlock()
+	es.last = err
+}
+
+// reportToStderr writes err to stderr unless another error was reported too
+// recently, in which case it is only counted. The count of errors that were
+// not reported is included in the next report.
+func (es *errorState) reportToStderr(err error) {
+	now := time.Now()
+
+	es.mtx.Lock()
+	if !es.lastReport.IsZero() && now.Sub(es.lastReport) < errorReportInterval {
+		es.suppressed++
+		es.mtx.Unlock()
+		return
+	}
+	suppressed := es.suppressed
+	es.suppressed = 0
+	es.lastReport = now
+	es.mtx.Unlock()
+
+	msg := "jellog: error writing log event: " + err.Error()
+	if suppressed > 0 {
+		msg += " (" + strconv.Itoa(suppressed) + " more errors since last report)"
+	}
+	msg += "\n"
+
+	mtxStderr.Lock()
+	defer mtxStderr.Unlock()
+	fmt.Fprint(os.Stderr, msg)
+}
+
+// handle passes err to handler if it is set, or otherwise reports it to stderr
+// as reportToStderr does. If err is nil, nothing happens. It is used for errors
+// that occur outside of any call that could return them, such as in
+// timer-driven output.
+func (es *errorState) handle(handler func(err error), err error) {
+	if err == nil {
+		return
+	}
+
+	if handler != nil {
+		handler(err)
+		return
+	}
+	es.reportToStderr(err)
+}
+
+// LastError returns the most recent non-nil error that occurred while
+// dispatching an event or a break to the Logger's Handlers, regardless of
+// which method was used to log it. If no error has occurred, nil is returned.
+//
+// Loggers created with Copy start with no error recorded.
+func (lg Logger[E]) LastError() error {
+	lg.errs.mtx.Lock()
+	defer lg.errs.mtx.Unlock()
+
+	return lg.errs.last
+}
+
+// HandleError applies the Logger's error policy to err, which is an error
+// returned by Output. If err is nil, nothing happens. Otherwise, it is passed
+// to the Logger's ErrorHandler, or if that is not set, reported to stderr at a
+// limited rate.
+//
+// This is used by all logging methods that do not return an error, and is
+// exported for use by code that calls Output directly but has no way of
+// returning the error itself.
+func (lg Logger[E]) HandleError(err error) {
+	lg.errs.handle(lg.opts.ErrorHandler, err)
+}
+
+// TryLog is the same as Log but returns the combined error of all Handlers
+// instead of passing it to the Logger's ErrorHandler.
+//
+// Every logging method of Logger that returns normally has a Try variant,
+// including the ln, Ctx and Err forms, such as TryInfoln, TryInfoCtx and
+// TryErr. The Fatal and Panic methods, which do not return, have none, and
+// neither do the Print methods, as TryLog, TryLogf and TryLogln at LvInfo do
+// the same.
+func (lg Logger[E]) TryLog(lv Level, msg any) error {
+	evt := lg.CreateEvent(lv, msg)
+	return lg.Output(2, evt)
+}
+
+// TryLogf is the same as Logf but returns the combined error of all Handlers
+// instead of passing it to the Logger's ErrorHandler.
+func (lg Logger[E]) TryLogf(lv Level, msg string, a ...interface{}) error {
+	evt := lg.CreateEvent(lv, fmt.Sprintf(msg, a...))
+	return lg.Output(2, evt)
+}
+
+// TryLogln is the same as Logln but returns the combined error of all Handlers
+// instead of passing it to the Logger's ErrorHandler.
+func (lg Logger[E]) TryLogln(lv Level, v ...any) error {
+	evt := lg.CreateEvent(lv, fmt.Sprintln(v...))
+	return lg.Output(2, evt)
+}
+
+// TryTrace is the same as Trace but returns the combined error of all Handlers
+// instead of passing it to the Logger's ErrorHandler.
+func (lg Logger[E]) TryTrace(msg E) error {
+	evt := lg.CreateEvent(LvTrace, msg)
+	return lg.Output(2, evt)
+}
+
+// TryTracef is the same as Tracef but returns the combined error of all
+// Handlers instead of passing it to the Logger's ErrorHandler.
+func (lg Logger[E]) TryTracef(msg string, a ...interface{}) error {
+	evt := lg.CreateEvent(LvTrace, fmt.Sprintf(msg, a...))
+	return lg.Output(2, evt)
+}
+
+// TryTraceln is the same as Traceln but returns the combined error of all
+// Handlers instead of passing it to the Logger's ErrorHandler.
+func (lg Logger[E]) TryTraceln(v ...any) error {
+	evt := lg.CreateEvent(LvTrace, fmt.Sprintln(v...))
+	return lg.Output(2, evt)
+}
+
+// TryDebug is the same as Debug but returns the combined error of all Handlers
+// instead of passing it to the Logger's ErrorHandler.
+func (lg Logger[E]) TryDebug(msg E) error {
+	evt := lg.CreateEvent(LvDebug, msg)
+	return lg.Output(2, evt)
+}
+
+// TryDebugf is the same as Debugf but returns the combined error of all
+// Handlers instead of passing it to the Logger's ErrorHandler.
+func (lg Logger[E]) TryDebugf(msg string, a ...interface{}) error {
+	evt := lg.CreateEvent(LvDebug, fmt.Sprintf(msg, a...))
+	return lg.Output(2, evt)
+}
+
+// TryDebugln is the same as Debugln but returns the combined error of all
+// Handlers instead of passing it to the Logger's ErrorHandler.
+func (lg Logger[E]) TryDebugln(v ...any) error {
+	evt := lg.CreateEvent(LvDebug, fmt.Sprintln(v...))
+	return lg.Output(2, evt)
+}
+
+// TryInfo is the same as Info but returns the combined error of all Handlers
+// instead of passing it to the Logger's ErrorHandler.
+func (lg Logger[E]) TryInfo(msg E) error {
+	evt := lg.CreateEvent(LvInfo, msg)
+	return lg.Output(2, evt)
+}
+
+// TryInfof is the same as Infof but returns the combined error of all Handlers
+// instead of passing it to the Logger's ErrorHandler.
+func (lg Logger[E]) TryInfof(msg string, a ...interface{}) error {
+	evt := lg.CreateEvent(LvInfo, fmt.Sprintf(msg, a...))
+	return lg.Output(2, evt)
+}
+
+// TryInfoln is the same as Infoln but returns the combined error of all
+// Handlers instead of passing it to the Logger's ErrorHandler.
+func (lg Logger[E]) TryInfoln(v ...any) error {
+	evt := lg.CreateEvent(LvInfo, fmt.Sprintln(v...))
+	return lg.Output(2, evt)
+}
+
+// TryWarn is the same as Warn but returns the combined error of all Handlers
+// instead of passing it to the Logger's ErrorHandler.
+func (lg Logger[E]) TryWarn(msg E) error {
+	evt := lg.CreateEvent(LvWarn, msg)
+	return lg.Output(2, evt)
+}
+
+// TryWarnf is the same as Warnf but returns the combined error of all Handlers
+// instead of passing it to the Logger's ErrorHandler.
+func (lg Logger[E]) TryWarnf(msg string, a ...interface{}) error {
+	evt := lg.CreateEvent(LvWarn, fmt.Sprintf(msg, a...))
+	return lg.Output(2, evt)
+}
+
+// TryWarnln is the same as Warnln but returns the combined error of all
+// Handlers instead of passing it to the Logger's ErrorHandler.
+func (lg Logger[E]) TryWarnln(v ...any) error {
+	evt := lg.CreateEvent(LvWarn, fmt.Sprintln(v...))
+	return lg.Output(2, evt)
+}
+
+// TryError is the same as Error but returns the combined error of all Handlers
+// instead of passing it to the Logger's ErrorHandler.
+func (lg Logger[E]) TryError(msg E) error {
+	evt := lg.CreateEvent(LvError, msg)
+	return lg.Output(2, evt)
+}
+
+// TryErrorf is the same as Errorf but returns the combined error of all
+// Handlers instead of passing it to the Logger's ErrorHandler.
+func (lg Logger[E]) TryErrorf(msg string, a ...interface{}) error {
+	evt := lg.CreateEvent(LvError, fmt.Sprintf(msg, a...))
+	return lg.Output(2, evt)
+}
+
+// TryErrorln is the same as Errorln but returns the combined error of all
+// Handlers instead of passing it to the Logger's ErrorHandler.
+func (lg Logger[E]) TryErrorln(v ...any) error {
+	evt := lg.CreateEvent(LvError, fmt.Sprintln(v...))
+	return lg.Output(2, evt)
+}
+
+// TryLogCtx is the same as LogCtx but returns the combined error of all
+// Handlers instead of passing it to the Logger's ErrorHandler.
+func (lg Logger[E]) TryLogCtx(ctx context.Context, lv Level, msg any) error {
+	evt := lg.CreateEventCtx(ctx, lv, msg)
+	return lg.Output(2, evt)
+}
+
+// TryLogfCtx is the same as LogfCtx but returns the combined error of all
+// Handlers instead of passing it to the Logger's ErrorHandler.
+func (lg Logger[E]) TryLogfCtx(ctx context.Context, lv Level, msg string, a ...interface{}) error {
+	evt := lg.CreateEventCtx(ctx, lv, fmt.Sprintf(msg, a...))
+	return lg.Output(2, evt)
+}
+
+// TryTraceCtx is the same as TraceCtx but returns the combined error of all
+// Handlers instead of passing it to the Logger's ErrorHandler.
+func (lg Logger[E]) TryTraceCtx(ctx context.Context, msg E) error {
+	evt := lg.CreateEventCtx(ctx, LvTrace, msg)
+	return lg.Output(2, evt)
+}
+
+// TryTracefCtx is the same as TracefCtx but returns the combined error of all
+// Handlers instead of passing it to the Logger's ErrorHandler.
+func (lg Logger[E]) TryTracefCtx(ctx context.Context, msg string, a ...interface{}) error {
+	evt := lg.CreateEventCtx(ctx, LvTrace, fmt.Sprintf(msg, a...))
+	return lg.Output(2, evt)
+}
+
+// TryDebugCtx is the same as DebugCtx but returns the combined error of all
+// Handlers instead of passing it to the Logger's ErrorHandler.
+func (lg Logger[E]) TryDebugCtx(ctx context.Context, msg E) error {
+	evt := lg.CreateEventCtx(ctx, LvDebug, msg)
+	return lg.Output(2, evt)
+}
+
+// TryDebugfCtx is the same as DebugfCtx but returns the combined error of all
+// Handlers instead of passing it to the Logger's ErrorHandler.
+func (lg Logger[E]) TryDebugfCtx(ctx context.Context, msg string, a ...interface{}) error {
+	evt := lg.CreateEventCtx(ctx, LvDebug, fmt.Sprintf(msg, a...))
+	return lg.Output(2, evt)
+}
+
+// TryInfoCtx is the same as InfoCtx but returns the combined error of all
+// Handlers instead of passing it to the Logger's ErrorHandler.
+func (lg Logger[E]) TryInfoCtx(ctx context.Context, msg E) error {
+	evt := lg.CreateEventCtx(ctx, LvInfo, msg)
+	return lg.Output(2, evt)
+}
+
+// TryInfofCtx is the same as InfofCtx but returns the combined error of all
+// Handlers instead of passing it to the Logger's ErrorHandler.
+func (lg Logger[E]) TryInfofCtx(ctx context.Context, msg string, a ...interface{}) error {
+	evt := lg.CreateEventCtx(ctx, LvInfo, fmt.Sprintf(msg, a...))
+	return lg.Output(2, evt)
+}
+
+// TryWarnCtx is the same as WarnCtx but returns the combined error of all
+// Handlers instead of passing it to the Logger's ErrorHandler.
+func (lg Logger[E]) TryWarnCtx(ctx context.Context, msg E) error {
+	evt := lg.CreateEventCtx(ctx, LvWarn, msg)
+	return lg.Output(2, evt)
+}
+
+// TryWarnfCtx is the same as WarnfCtx but returns the combined error of all
+// Handlers instead of passing it to the Logger's ErrorHandler.
+func (lg Logger[E]) TryWarnfCtx(ctx context.Context, msg string, a ...interface{}) error {
+	evt := lg.CreateEventCtx(ctx, LvWarn, fmt.Sprintf(msg, a...))
+	return lg.Output(2, evt)
+}
+
+// TryErrorCtx is the same as ErrorCtx but returns the combined error of all
+// Handlers instead of passing it to the Logger's ErrorHandler.
+func (lg Logger[E]) TryErrorCtx(ctx context.Context, msg E) error {
+	evt := lg.CreateEventCtx(ctx, LvError, msg)
+	return lg.Output(2, evt)
+}
+
+// TryErrorfCtx is the same as ErrorfCtx but returns the combined error of all
+// Handlers instead of passing it to the Logger's ErrorHandler.
+func (lg Logger[E]) TryErrorfCtx(ctx context.Context, msg string, a ...interface{}) error {
+	evt := lg.CreateEventCtx(ctx, LvError, fmt.Sprintf(msg, a...))
+	return lg.Output(2, evt)
+}
+
+// TryErr is the same as Err but returns the combined error of all Handlers
+// instead of passing it to the Logger's ErrorHandler.
+func (lg Logger[E]) TryErr(err error, msg ...any) error {
+	evt := lg.errEvent(LvError, err, fmt.Sprint(msg...))
+	return lg.Output(2, evt)
+}
+
+// TryErrf is the same as Errf but returns the combined error of all Handlers
+// instead of passing it to the Logger's ErrorHandler.
+func (lg Logger[E]) TryErrf(err error, format string, a ...interface{}) error {
+	evt := lg.errEvent(LvError, err, fmt.Sprintf(format, a...))
+	return lg.Output(2, evt)
+}
+
+// TryLogErr is the same as LogErr but returns the combined error of all
+// Handlers instead of passing it to the Logger's ErrorHandler.
+func (lg Logger[E]) TryLogErr(lv Level, err error, msg ...any) error {
+	evt := lg.errEvent(lv, err, fmt.Sprint(msg...))
+	return lg.Output(2, evt)
+}
@@ -0,0 +1,153 @@
+package jellog_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/dekarrin/jellog"
+	"github.com/dekarrin/jellog/jellogtest"
+)
+
+func Test_Logger_Try(t *testing.T) {
+	ctx := context.Background()
+	cause := errors.New("disk full")
+
+	testCases := []struct {
+		name     string
+		try      func(lg jellog.Logger[string]) error
+		expectLv jellog.Level
+		expect   string
+	}{
+		{
+			name:     "TryLog",
+			try:      func(lg jellog.Logger[string]) error { return lg.TryLog(jellog.LvWarn, "a") },
+			expectLv: jellog.LvWarn,
+			expect:   "a",
+		},
+		{
+			name:     "TryLogf",
+			try:      func(lg jellog.Logger[string]) error { return lg.TryLogf(jellog.LvWarn, "a%d", 1) },
+			expectLv: jellog.LvWarn,
+			expect:   "a1",
+		},
+		{
+			name:     "TryLogln",
+			try:      func(lg jellog.Logger[string]) error { return lg.TryLogln(jellog.LvWarn, "a", 1) },
+			expectLv: jellog.LvWarn,
+			expect:   "a 1\n",
+		},
+		{
+			name:     "TryTraceln",
+			try:      func(lg jellog.Logger[string]) error { return lg.TryTraceln("a", 1) },
+			expectLv: jellog.LvTrace,
+			expect:   "a 1\n",
+		},
+		{
+			name:     "TryDebugln",
+			try:      func(lg jellog.Logger[string]) error { return lg.TryDebugln("a", 1) },
+			expectLv: jellog.LvDebug,
+			expect:   "a 1\n",
+		},
+		{
+			name:     "TryInfo",
+			try:      func(lg jellog.Logger[string]) error { return lg.TryInfo("a") },
+			expectLv: jellog.LvInfo,
+			expect:   "a",
+		},
+		{
+			name:     "TryInfoln",
+			try:      func(lg jellog.Logger[string]) error { return lg.TryInfoln("a", 1) },
+			expectLv: jellog.LvInfo,
+			expect:   "a 1\n",
+		},
+		{
+			name:     "TryWarnln",
+			try:      func(lg jellog.Logger[string]) error { return lg.TryWarnln("a", 1) },
+			expectLv: jellog.LvWarn,
+			expect:   "a 1\n",
+		},
+		{
+			name:     "TryErrorln",
+			try:      func(lg jellog.Logger[string]) error { return lg.TryErrorln("a", 1) },
+			expectLv: jellog.LvError,
+			expect:   "a 1\n",
+		},
+		{
+			name:     "TryLogCtx",
+			try:      func(lg jellog.Logger[string]) error { return lg.TryLogCtx(ctx, jellog.LvWarn, "a") },
+			expectLv: jellog.LvWarn,
+			expect:   "a",
+		},
+		{
+			name:     "TryInfoCtx",
+			try:      func(lg jellog.Logger[string]) error { return lg.TryInfoCtx(ctx, "a") },
+			expectLv: jellog.LvInfo,
+			expect:   "a",
+		},
+		{
+			name:     "TryErrorfCtx",
+			try:      func(lg jellog.Logger[string]) error { return lg.TryErrorfCtx(ctx, "a%d", 1) },
+			expectLv: jellog.LvError,
+			expect:   "a1",
+		},
+		{
+			name:     "TryErr",
+			try:      func(lg jellog.Logger[string]) error { return lg.TryErr(cause, "saving") },
+			expectLv: jellog.LvError,
+			expect:   "saving: disk full",
+		},
+		{
+			name:     "TryErrf",
+			try:      func(lg jellog.Logger[string]) error { return lg.TryErrf(cause, "saving %d", 1) },
+			expectLv: jellog.LvError,
+			expect:   "saving 1: disk full",
+		},
+		{
+			name:     "TryLogErr",
+			try:      func(lg jellog.Logger[string]) error { return lg.TryLogErr(jellog.LvWarn, cause) },
+			expectLv: jellog.LvWarn,
+			expect:   "disk full",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			errWrite := errors.New("cannot write")
+			var reported []error
+
+			rec := jellogtest.NewRecorder[string](nil)
+			fh := &failingHandler{
+				Recorder: jellogtest.NewRecorder[string](nil),
+				fail:     func(jellog.Event[string]) bool { return true },
+				err:      errWrite,
+			}
+			lg := jellog.New(jellog.Defaults[string]().WithErrorHandler(func(err error) {
+				reported = append(reported, err)
+			}))
+			lg.AddHandler(jellog.LvTrace, rec)
+			lg.AddHandler(jellog.LvTrace, fh)
+
+			err := tc.try(lg)
+
+			if !errors.Is(err, errWrite) {
+				t.Errorf("expected error to wrap %v, got %v", errWrite, err)
+			}
+			if len(reported) > 0 {
+				t.Errorf("expected nothing to be reported to ErrorHandler, got %v", reported)
+			}
+
+			evts := rec.Events()
+			if len(evts) != 1 {
+				t.Fatalf("expected 1 event, got %d", len(evts))
+			}
+			if evts[0].Level != tc.expectLv || evts[0].Message != tc.expect {
+				t.Errorf("expected %v event %q, got %v event %q", tc.expectLv, tc.expect, evts[0].Level, evts[0].Message)
+			}
+			if file, _, _, _ := evts[0].Caller(); filepath.Base(file) != "errors_test.go" {
+				t.Errorf("expected caller in errors_test.go, got %q", file)
+			}
+		})
+	}
+}
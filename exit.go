@@ -0,0 +1,129 @@
+package jellog
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultExitTimeout is how long a Logger waits for exit hooks to run and for
+// its Handlers to be flushed and closed before exiting the program, if no
+// other timeout is set in its Options.
+const DefaultExitTimeout = 5 * time.Second
+
+var (
+	exitHooksMtx sync.Mutex
+	exitHooks    []func(ctx context.Context)
+)
+
+// RegisterExitHook adds a function to be called when a Logger exits the
+// program, as Fatal does. Hooks are called in the reverse order they were
+// registered, before any Handlers are flushed and closed, so they may still log
+// messages. Each is given a context that is done when the Logger's exit timeout
+// elapses.
+//
+// Exit hooks are not called when the program exits in any other way.
+func RegisterExitHook(hook func(ctx context.Context)) {
+	exitHooksMtx.Lock()
+	defer exitHooksMtx.Unlock()
+
+	exitHooks = append(exitHooks, hook)
+}
+
+// Exit shuts down logging and then exits the program with the given status
+// code. This is what Fatal and its variants use to exit after logging their
+// message.
+//
+// Before exiting, the registered exit hooks are called and then the Logger is
+// closed with Close, which flushes and closes its Handlers. This is given no
+// more than the Logger's ExitTimeout, after which the program exits
+// regardless. The program is exited by calling the Logger's
+// ExitFunc, which is os.Exit unless set otherwise in Options; if ExitFunc
+// returns, so does Exit.
+//
+// If ExitFunc is set, the program may keep running after it is called, so the
+// Logger is only flushed with Flush rather than closed, and its Handlers can
+// still be used afterwards.
+func (lg Logger[E]) Exit(code int) {
+	timeout := lg.opts.ExitTimeout
+	if timeout <= 0 {
+		timeout = DefaultExitTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	exit := lg.opts.ExitFunc
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		runExitHooks(ctx)
+		if exit == nil {
+			lg.HandleError(lg.Close(ctx))
+		} else {
+			lg.HandleError(lg.Flush(ctx))
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		mtxStderr.Lock()
+		fmt.Fprintf(os.Stderr, "jellog: gave up on shutting down logging after %s\n", timeout)
+		mtxStderr.Unlock()
+	}
+
+	if exit == nil {
+		exit = os.Exit
+	}
+	exit(code)
+}
+
+// runExitHooks calls every registered exit hook, most recent first.
+func runExitHooks(ctx context.Context) {
+	exitHooksMtx.Lock()
+	hooks := append([]func(ctx context.Context){}, exitHooks...)
+	exitHooksMtx.Unlock()
+
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hooks[i](ctx)
+	}
+}
+
+// FatalExit logs a message at severity level FATAL and then exits the program
+// with the given status code. Supplementary information is gathered along with
+// msg into an Event which is then passed to the appropriate Handlers.
+//
+// If msg is of type E, then it is used directly. If it is not, it is converted
+// to the proper type by using the Logger's Converter function.
+func (lg Logger[E]) FatalExit(code int, msg E) {
+	lg.fatal(2, code, msg)
+}
+
+// FatalExitf logs a formatted message at severity level FATAL and then exits
+// the program with the given status code. Supplementary information is
+// gathered along with msg into an Event which is then passed to the appropriate
+// Handlers.
+//
+// The message is created as a formatted string, and is then converted to the
+// type of logged object handled by the Logger by using the Logger's Converter
+// function.
+func (lg Logger[E]) FatalExitf(code int, msg string, a ...interface{}) {
+	lg.fatal(2, code, fmt.Sprintf(msg, a...))
+}
+
+// FatalExit logs a message using the default logger at severity level FATAL
+// and then exits the program with the given status code. Arguments are handled
+// in the manner of fmt.Print.
+func FatalExit(code int, v ...any) {
+	std.fatal(2, code, fmt.Sprint(v...))
+}
+
+// FatalExitf logs a message using the default logger at severity level FATAL
+// and then exits the program with the given status code. Arguments are handled
+// in the manner of fmt.Printf.
+func FatalExitf(code int, format string, v ...any) {
+	std.fatal(2, code, fmt.Sprintf(format, v...))
+}
@@ -0,0 +1,112 @@
+package jellog_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/dekarrin/jellog"
+	"github.com/dekarrin/jellog/jellogtest"
+)
+
+// closeRecorder records events like a Recorder, and records calls to Flush and
+// Close. Output fails once it has been closed.
+type closeRecorder struct {
+	*jellogtest.Recorder[string]
+
+	mtx     sync.Mutex
+	flushes int
+	closed  bool
+}
+
+func (cr *closeRecorder) Output(calldepth int, evt jellog.Event[string]) error {
+	cr.mtx.Lock()
+	closed := cr.closed
+	cr.mtx.Unlock()
+	if closed {
+		return errors.New("output after close")
+	}
+	return cr.Recorder.Output(calldepth+1, evt)
+}
+
+func (cr *closeRecorder) Flush(ctx context.Context) error {
+	cr.mtx.Lock()
+	defer cr.mtx.Unlock()
+	cr.flushes++
+	return nil
+}
+
+func (cr *closeRecorder) Close(ctx context.Context) error {
+	cr.mtx.Lock()
+	defer cr.mtx.Unlock()
+	cr.closed = true
+	if os.Getenv("JELLOG_TEST_EXIT") != "" {
+		os.Stdout.WriteString("handler closed\n")
+	}
+	return nil
+}
+
+func Test_Logger_Exit_exitFunc(t *testing.T) {
+	var codes []int
+	cr := &closeRecorder{Recorder: jellogtest.NewRecorder[string](nil)}
+	lg := jellog.New(jellog.Defaults[string]().WithExitFunc(func(code int) {
+		codes = append(codes, code)
+	}))
+	lg.AddHandler(jellog.LvTrace, cr)
+
+	var hookRan bool
+	jellog.RegisterExitHook(func(ctx context.Context) {
+		hookRan = true
+	})
+
+	lg.FatalExit(3, "shutting down")
+
+	if len(codes) != 1 || codes[0] != 3 {
+		t.Fatalf("expected ExitFunc to be called with 3, got %v", codes)
+	}
+	if !hookRan {
+		t.Errorf("expected exit hook to run")
+	}
+	jellogtest.AssertHasEvent(t, cr.Recorder, jellog.LvFatal, "shutting down")
+
+	// with ExitFunc set, handlers are flushed but left open, so the Logger
+	// still works if ExitFunc returns
+	cr.mtx.Lock()
+	if cr.flushes != 1 || cr.closed {
+		t.Errorf("expected handler to be flushed once and not closed, got %d flushes, closed=%v", cr.flushes, cr.closed)
+	}
+	cr.mtx.Unlock()
+
+	lg.Info("after exit")
+	jellogtest.AssertHasEvent(t, cr.Recorder, jellog.LvInfo, "after exit")
+	if err := lg.LastError(); err != nil {
+		t.Errorf("expected no error logging after Exit, got %v", err)
+	}
+}
+
+func Test_Logger_Exit_default(t *testing.T) {
+	if os.Getenv("JELLOG_TEST_EXIT") != "" {
+		cr := &closeRecorder{Recorder: jellogtest.NewRecorder[string](nil)}
+		lg := jellog.New(jellog.Defaults[string]())
+		lg.AddHandler(jellog.LvTrace, cr)
+		lg.Exit(3)
+		return
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^Test_Logger_Exit_default$")
+	cmd.Env = append(os.Environ(), "JELLOG_TEST_EXIT=1")
+	out, err := cmd.Output()
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 3 {
+		t.Fatalf("expected process to exit with status 3, got %v", err)
+	}
+	// with the default os.Exit, handlers are closed before exiting
+	if !strings.Contains(string(out), "handler closed") {
+		t.Errorf("expected handler to be closed before exiting, got output %q", out)
+	}
+}
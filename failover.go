@@ -0,0 +1,164 @@
+package jellog
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// FailoverOptions is used to control the behavior of a FailoverHandler. It is
+// passed to NewFailoverHandler as an optional argument.
+type FailoverOptions struct {
+	// RetryAfter is how long a Handler that fails is considered unhealthy.
+	// Unhealthy Handlers are passed over in favor of healthy ones until it
+	// elapses, after which they are tried again in their normal order. If not
+	// set, 30 seconds is used.
+	RetryAfter time.Duration
+
+	// OnSwitch, if set, is called whenever a different Handler becomes the
+	// active one. It is given the indexes of the previously and newly active
+	// Handlers, and the error that caused the switch, which is nil if the
+	// switch is back to a Handler that has recovered. It is called from within
+	// Output and must not log to the FailoverHandler.
+	OnSwitch func(from, to int, err error)
+}
+
+// FailoverHandler is a Handler that wraps an ordered list of Handlers and
+// passes each event to only the first of them that accepts it. The first
+// Handler is the primary and the rest are fallbacks in order of preference.
+//
+// When a Handler returns an error from Output, the event is passed to the next
+// one, and the failed Handler is marked unhealthy for the configured
+// RetryAfter. Unhealthy Handlers are skipped until that time has passed, at
+// which point they are tried again; this way, logging returns to the primary
+// once it recovers. If every healthy Handler fails, the unhealthy ones are
+// tried as a last resort before giving up, so an event is only lost if every
+// Handler fails to output it.
+//
+// A FailoverHandler is safe to use from multiple goroutines. It should be
+// created with NewFailoverHandler.
+type FailoverHandler[E any] struct {
+	hs   []Handler[E]
+	opts FailoverOptions
+
+	mtx       sync.Mutex
+	downUntil []time.Time
+	active    int
+}
+
+// NewFailoverHandler creates a FailoverHandler that passes events to the
+// Handlers in hs, in order of preference. It panics if hs is empty.
+//
+// To use the default set of FailoverOptions, pass nil for opts.
+func NewFailoverHandler[E any](hs []Handler[E], opts *FailoverOptions) *FailoverHandler[E] {
+	if len(hs) < 1 {
+		panic("NewFailoverHandler requires at least one Handler")
+	}
+
+	var o FailoverOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.RetryAfter <= 0 {
+		o.RetryAfter = 30 * time.Second
+	}
+
+	return &FailoverHandler[E]{
+		hs:        append([]Handler[E]{}, hs...),
+		opts:      o,
+		downUntil: make([]time.Time, len(hs)),
+	}
+}
+
+// HandlerOptions returns the options of the primary Handler.
+func (fh *FailoverHandler[E]) HandlerOptions() HandlerOptions[E] {
+	return fh.hs[0].HandlerOptions()
+}
+
+// Active returns the index and Handler that most recently accepted an event.
+// Before any events have been output, this is the primary.
+func (fh *FailoverHandler[E]) Active() (int, Handler[E]) {
+	fh.mtx.Lock()
+	defer fh.mtx.Unlock()
+
+	return fh.active, fh.hs[fh.active]
+}
+
+// Healthy returns whether the Handler at index i is currently considered
+// healthy, that is, it has not failed within the last RetryAfter.
+func (fh *FailoverHandler[E]) Healthy(i int) bool {
+	fh.mtx.Lock()
+	defer fh.mtx.Unlock()
+
+	return !time.Now().Before(fh.downUntil[i])
+}
+
+// InsertBreak inserts a break in the active Handler.
+func (fh *FailoverHandler[E]) InsertBreak() error {
+	_, h := fh.Active()
+	return h.InsertBreak()
+}
+
+// Output passes a log event to the first healthy Handler that accepts it. If
+// every Handler fails, the combined error of all of them is returned.
+//
+// The calldepth argument is used for recovering the program counter. It should
+// be supplied with the number of levels into the jellog package that the caller
+// has reached, with the externally called function counting as 1.
+func (fh *FailoverHandler[E]) Output(calldepth int, evt Event[E]) error {
+	if evt.PC == 0 {
+		evt.PC = callerPC(calldepth)
+	}
+
+	now := time.Now()
+	fh.mtx.Lock()
+	var healthy, unhealthy []int
+	for i := range fh.hs {
+		if now.Before(fh.downUntil[i]) {
+			unhealthy = append(unhealthy, i)
+		} else {
+			healthy = append(healthy, i)
+		}
+	}
+	fh.mtx.Unlock()
+
+	var fullErr error
+	for _, i := range append(healthy, unhealthy...) {
+		err := fh.hs[i].Output(calldepth+1, evt)
+		if err == nil {
+			fh.succeeded(i, fullErr)
+			return nil
+		}
+		fullErr = joinHandlerErr(fullErr, fmt.Errorf("failover target %d: %w", i, err))
+		fh.failed(i)
+	}
+
+	return fullErr
+}
+
+// succeeded records that the Handler at index i accepted an event after
+// earlier ones failed with err.
+func (fh *FailoverHandler[E]) succeeded(i int, err error) {
+	fh.mtx.Lock()
+	prev := fh.active
+	fh.active = i
+	fh.downUntil[i] = time.Time{}
+	fh.mtx.Unlock()
+
+	if prev != i && fh.opts.OnSwitch != nil {
+		fh.opts.OnSwitch(prev, i, err)
+	}
+}
+
+// failed marks the Handler at index i as unhealthy.
+func (fh *FailoverHandler[E]) failed(i int) {
+	fh.mtx.Lock()
+	defer fh.mtx.Unlock()
+
+	fh.downUntil[i] = time.Now().Add(fh.opts.RetryAfter)
+}
+
+// WrappedHandlers returns all of the Handlers that fh fails over between.
+func (fh *FailoverHandler[E]) WrappedHandlers() []Handler[E] {
+	return append([]Handler[E]{}, fh.hs...)
+}
@@ -0,0 +1,166 @@
+package jellog_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dekarrin/jellog"
+	"github.com/dekarrin/jellog/jellogtest"
+)
+
+// switchHandler is a failingHandler whose failure can be turned on and off,
+// and that counts how many times it was called.
+type switchHandler struct {
+	failingHandler
+	down  atomic.Bool
+	calls atomic.Int32
+}
+
+func newSwitchHandler(err error) *switchHandler {
+	sh := &switchHandler{}
+	sh.failingHandler = failingHandler{
+		Recorder: jellogtest.NewRecorder[string](nil),
+		fail: func(jellog.Event[string]) bool {
+			sh.calls.Add(1)
+			return sh.down.Load()
+		},
+		err: err,
+	}
+	return sh
+}
+
+type switchRecord struct {
+	from, to int
+	err      error
+}
+
+func Test_FailoverHandler_Output(t *testing.T) {
+	errPrimary := errors.New("primary is down")
+	primary := newSwitchHandler(errPrimary)
+	secondary := newSwitchHandler(errors.New("secondary is down"))
+
+	var mtx sync.Mutex
+	var switches []switchRecord
+	fh := jellog.NewFailoverHandler([]jellog.Handler[string]{primary, secondary}, &jellog.FailoverOptions{
+		RetryAfter: 50 * time.Millisecond,
+		OnSwitch: func(from, to int, err error) {
+			mtx.Lock()
+			defer mtx.Unlock()
+			switches = append(switches, switchRecord{from, to, err})
+		},
+	})
+	evt := jellog.Event[string]{Level: jellog.LvInfo, Message: "m"}
+
+	// primary fails and output moves to the secondary
+	primary.down.Store(true)
+	if err := fh.Output(1, evt); err != nil {
+		t.Fatalf("expected secondary to accept event, got %v", err)
+	}
+	if primary.Len() != 0 || secondary.Len() != 1 {
+		t.Fatalf("expected event to go to secondary, got %d/%d", primary.Len(), secondary.Len())
+	}
+	if i, _ := fh.Active(); i != 1 {
+		t.Errorf("expected secondary to be active, got %d", i)
+	}
+	if fh.Healthy(0) || !fh.Healthy(1) {
+		t.Errorf("expected only primary to be unhealthy")
+	}
+
+	// the unhealthy primary is not tried again until RetryAfter elapses
+	fh.Output(1, evt)
+	if n := primary.calls.Load(); n != 1 {
+		t.Errorf("expected unhealthy primary to be skipped, but it was called %d times", n)
+	}
+	if secondary.Len() != 2 {
+		t.Errorf("expected 2 events for secondary, got %d", secondary.Len())
+	}
+
+	// primary recovers and output returns to it once RetryAfter elapses
+	primary.down.Store(false)
+	waitFor(t, "primary to be retried", func() bool { return fh.Healthy(0) })
+	if err := fh.Output(1, evt); err != nil {
+		t.Fatalf("Output: %v", err)
+	}
+	if primary.Len() != 1 || secondary.Len() != 2 {
+		t.Errorf("expected event to return to primary, got %d/%d", primary.Len(), secondary.Len())
+	}
+	if i, _ := fh.Active(); i != 0 {
+		t.Errorf("expected primary to be active, got %d", i)
+	}
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	if len(switches) != 2 {
+		t.Fatalf("expected 2 switches, got %v", switches)
+	}
+	if switches[0].from != 0 || switches[0].to != 1 || !errors.Is(switches[0].err, errPrimary) {
+		t.Errorf("expected switch from 0 to 1 for primary error, got %v", switches[0])
+	}
+	if switches[1].from != 1 || switches[1].to != 0 || switches[1].err != nil {
+		t.Errorf("expected switch back from 1 to 0 without error, got %v", switches[1])
+	}
+}
+
+func Test_FailoverHandler_Output_unhealthyLastResort(t *testing.T) {
+	primary := newSwitchHandler(errors.New("primary is down"))
+	secondary := newSwitchHandler(errors.New("secondary is down"))
+	fh := jellog.NewFailoverHandler([]jellog.Handler[string]{primary, secondary}, &jellog.FailoverOptions{RetryAfter: time.Hour})
+	evt := jellog.Event[string]{Level: jellog.LvInfo, Message: "m"}
+
+	primary.down.Store(true)
+	fh.Output(1, evt)
+
+	// the primary is still marked unhealthy, but it is tried once the
+	// secondary fails as well
+	primary.down.Store(false)
+	secondary.down.Store(true)
+	if err := fh.Output(1, evt); err != nil {
+		t.Fatalf("expected unhealthy primary to accept event, got %v", err)
+	}
+	if primary.Len() != 1 {
+		t.Errorf("expected event to go to primary, got %d events", primary.Len())
+	}
+}
+
+func Test_FailoverHandler_Output_allFail(t *testing.T) {
+	errs := []error{errors.New("a is down"), errors.New("b is down"), errors.New("c is down")}
+	var hs []jellog.Handler[string]
+	for _, err := range errs {
+		sh := newSwitchHandler(err)
+		sh.down.Store(true)
+		hs = append(hs, sh)
+	}
+	fh := jellog.NewFailoverHandler(hs, nil)
+
+	err := fh.Output(1, jellog.Event[string]{Level: jellog.LvInfo, Message: "m"})
+
+	if err == nil {
+		t.Fatalf("expected error when every handler fails")
+	}
+	expect := "handler: failover target 0: a is down\nhandler: failover target 1: b is down\nhandler: failover target 2: c is down"
+	if err.Error() != expect {
+		t.Errorf("expected error %q, got %q", expect, err.Error())
+	}
+	for _, e := range errs {
+		if !errors.Is(err, e) {
+			t.Errorf("expected error to wrap %v", e)
+		}
+	}
+	for i := range hs {
+		if fh.Healthy(i) {
+			t.Errorf("expected handler %d to be unhealthy", i)
+		}
+	}
+}
+
+func Test_NewFailoverHandler_noHandlers(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected NewFailoverHandler to panic")
+		}
+	}()
+	jellog.NewFailoverHandler[string](nil, nil)
+}
@@ -1,6 +1,7 @@
 package jellog
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"sync"
@@ -15,9 +16,10 @@ import (
 // would thus be handled in an operating system-dependant way. If this is to be
 // avoided, users must ensure that only one FileHandler is opened per file.
 type FileHandler struct {
-	opts HandlerOptions[string]
-	f    *os.File
-	mtx  sync.Mutex
+	opts   HandlerOptions[string]
+	f      *os.File
+	mtx    sync.Mutex
+	closed bool
 }
 
 // OpenFile gets a File-based logger ready for logging. If the file already
@@ -65,6 +67,9 @@ func (fh *FileHandler) InsertBreak() error {
 	fh.mtx.Lock()
 	defer fh.mtx.Unlock()
 
+	if err := fh.checkOpen(); err != nil {
+		return err
+	}
 	_, err := fh.f.Write(buf)
 
 	return err
@@ -85,10 +90,6 @@ func (fh *FileHandler) HandlerOptions() HandlerOptions[string] {
 // be supplied with the number of levels into the jellog package that the caller
 // has reached, with the externally called function counting as 1.
 func (fh *FileHandler) Output(calldepth int, evt Event[string]) error {
-	if fh.f == nil {
-		return fmt.Errorf("Output() called on FileHandler created without OpenFile")
-	}
-
 	// chain our component with the event's component if we have one
 	if fh.opts.Component != "" {
 		if evt.Component != "" {
@@ -107,6 +108,51 @@ func (fh *FileHandler) Output(calldepth int, evt Event[string]) error {
 	fh.mtx.Lock()
 	defer fh.mtx.Unlock()
 
+	if err := fh.checkOpen(); err != nil {
+		return err
+	}
 	_, err := fh.f.Write(buf)
 	return err
 }
+
+// Flush commits everything written to the file so far to stable storage. Writes
+// to the file are not buffered, so this is only needed to guard against the
+// loss of data that the operating system has not yet written to disk.
+func (fh *FileHandler) Flush(ctx context.Context) error {
+	fh.mtx.Lock()
+	defer fh.mtx.Unlock()
+
+	if err := fh.checkOpen(); err != nil {
+		return err
+	}
+	return fh.f.Sync()
+}
+
+// Close closes the file that fh was opened on. Any further calls to Output or
+// InsertBreak return an error. Closing an already-closed FileHandler has no
+// effect.
+func (fh *FileHandler) Close(ctx context.Context) error {
+	fh.mtx.Lock()
+	defer fh.mtx.Unlock()
+
+	if fh.closed {
+		return nil
+	}
+	if err := fh.checkOpen(); err != nil {
+		return err
+	}
+
+	fh.closed = true
+	return fh.f.Close()
+}
+
+// checkOpen returns an error if fh cannot be written to. fh.mtx must be held.
+func (fh *FileHandler) checkOpen() error {
+	if fh.f == nil {
+		return fmt.Errorf("FileHandler was created without OpenFile")
+	}
+	if fh.closed {
+		return fmt.Errorf("FileHandler is closed")
+	}
+	return nil
+}
@@ -1,9 +1,11 @@
 package jellog
 
 import (
-	"fmt"
+	"strconv"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
 // Formatter converts Events into a series of formatted bytes ready for writing
@@ -21,34 +23,125 @@ type Formatter[E any] interface {
 // single line with info in a file. A newline character is automatically added
 // if the logged message doesn't already have one, and the result is converted
 // to UTF-8 bytes.
+//
+// Messages that span multiple lines or contain control characters can be used
+// to forge what look like additional log entries. The Messages field controls
+// how LineFormat guards against this; by default, messages that were created by
+// a Logger's Converter are treated as untrusted and have their continuation
+// lines indented and control characters escaped.
+//
+// LineFormat is a TerminalFormatter; level names and components can be shown in
+// color when writing to a terminal. See the Color field for details.
 type LineFormat struct {
 	// UTC is whether to give the timestamp in each log entry in UTC time as
-	// opposed to the local timezone.
+	// opposed to the local timezone. It has no effect if Location is set.
 	UTC bool
 
 	// ShowMicroseconds is whether to include microseconds in the timestamp of a
 	// log entry.
+	//
+	// Deprecated: Set TimePrecision to PrecisionMicroseconds instead. This
+	// field is only used if TimePrecision is not set.
 	ShowMircoseconds bool
+
+	// Location is the time zone that the timestamp of each log entry is given
+	// in. If not set, the local time zone is used, unless UTC is set.
+	Location *time.Location
+
+	// TimeLayout is the layout of the timestamp of each log entry, given in the
+	// same form as for time.Format. If not set, the same layout as the standard
+	// Go log library is used, "2006/01/02 15:04:05".
+	//
+	// The default layout and time.RFC3339 are formatted directly without
+	// parsing the layout, and have TimePrecision fractional digits added to
+	// their seconds. Any other layout is formatted with time.AppendFormat and
+	// must include its own fractional seconds if they are wanted.
+	TimeLayout string
+
+	// TimePrecision is how many fractional digits of the second are included
+	// in the timestamp of each log entry. It only applies to the default
+	// TimeLayout and to time.RFC3339.
+	TimePrecision TimePrecision
+
+	// OmitTime is whether to leave the timestamp out of each log entry
+	// entirely. This is useful when the destination adds its own timestamps,
+	// such as when running under systemd.
+	OmitTime bool
+
+	// Color is whether to show level names and components in color using ANSI
+	// escape sequences. The default, ColorAuto, only uses color when a Handler
+	// that writes to a terminal (such as StderrHandler when stderr is a
+	// terminal) requests it via ForTerminal; Handlers that write elsewhere
+	// never use color with ColorAuto. Level names are shown in the color set
+	// for the level with SetLevelColor.
+	Color ColorMode
+
+	// ComponentColor is the color that components are shown in when color is
+	// in use. If not set, ColorBlue is used.
+	ComponentColor Color
+
+	// Messages is how the logged message is written out. The default,
+	// MessageAuto, writes messages given directly to the Logger as-is and uses
+	// MessageIndent for messages created by the Logger's Converter.
+	Messages MessageMode
+
+	// ContinuationMarker is the text placed at the start of each continuation
+	// line of a message when MessageIndent is in use. If not set,
+	// DefaultContinuationMarker is used.
+	ContinuationMarker string
+}
+
+// ForTerminal returns a copy of lf that uses color if lf's Color mode calls for
+// it given whether the destination is a terminal.
+func (lf LineFormat) ForTerminal(isTerminal bool) Formatter[string] {
+	if colorEnabled(lf.Color, isTerminal) {
+		lf.Color = ColorAlways
+	} else {
+		lf.Color = ColorNever
+	}
+	return lf
 }
 
 // Format formats a log event as a line ending witih '\n' that has time, level,
-// and other information at the start of the line.
+// and other information at the start of the line. If the event was logged for
+// an error that wraps others, the wrapped errors are written on indented lines
+// following the message, and then the stack trace if the event has one.
 func (lf LineFormat) Format(evt Event[string]) []byte {
-	msg := evt.Message
+	msg := formatMessage(evt.Message, lf.Messages, evt.Converted, lf.ContinuationMarker)
 
 	if !strings.HasSuffix(msg, "\n") {
 		msg += "\n"
 	}
-	timeStr := formatTime(evt.Time, lf.UTC, lf.ShowMircoseconds)
 
-	var formatted string
+	var levelColor, compColor Color
+	if lf.Color == ColorAlways {
+		levelColor = LevelColor(evt.Level)
+		compColor = lf.ComponentColor
+		if compColor == "" {
+			compColor = ColorBlue
+		}
+	}
+
+	buf := make([]byte, 0, len(evt.Component)+len(msg)+48)
+	if !lf.OmitTime {
+		buf = lf.appendTime(buf, evt.Time)
+		buf = append(buf, ' ')
+	}
+	buf = appendColored(buf, levelColor, evt.Level.Name)
+	for i := utf8.RuneCountInString(evt.Level.Name); i < 5; i++ {
+		buf = append(buf, ' ')
+	}
+	buf = append(buf, ' ')
 	if evt.Component != "" {
-		formatted = fmt.Sprintf("%[1]s %-5[2]s (%[4]s) %[3]s", timeStr, evt.Level.Name, msg, evt.Component)
-	} else {
-		formatted = fmt.Sprintf("%[1]s %-5[2]s %[3]s", timeStr, evt.Level.Name, msg)
+		buf = append(buf, '(')
+		buf = appendColored(buf, compColor, evt.Component)
+		buf = append(buf, ") "...)
 	}
+	buf = append(buf, msg...)
+	buf = appendCauses(buf, evt.Causes)
+	buf = appendStack(buf, evt.Stack)
 
-	return []byte(formatted)
+	return buf
 }
 
 // Break returns the newline character '\n'.
@@ -56,35 +149,240 @@ func (lf LineFormat) Break() []byte {
 	return []byte{'\n'}
 }
 
-func formatTime(t time.Time, utc bool, micros bool) string {
-	var buf []byte
+// DefaultContinuationMarker is the text placed at the start of each
+// continuation line of a message written with MessageIndent when no other
+// marker is configured.
+const DefaultContinuationMarker = "    | "
+
+// MessageMode is how a text-based Formatter writes out the logged message of an
+// event, in particular any newlines or other control characters within it.
+type MessageMode int
+
+const (
+	// MessageAuto uses MessageRaw for messages that were given directly to a
+	// Logger and MessageIndent for messages that were created by a Logger's
+	// Converter.
+	MessageAuto MessageMode = iota
+
+	// MessageRaw writes the message exactly as it was logged. Embedded
+	// newlines and control characters are written out unchanged.
+	MessageRaw
+
+	// MessageIndent writes each line of the message after the first on its
+	// own line, starting with a continuation marker so that it cannot be
+	// mistaken for a separate log entry. Control characters other than
+	// newlines and tabs are escaped as in MessageEscape.
+	MessageIndent
+
+	// MessageEscape writes the message on a single line, with newlines, tabs,
+	// and other control characters replaced with Go-style escape sequences
+	// such as \n or \x1b.
+	MessageEscape
+
+	// MessageQuote writes the message on a single line as a double-quoted Go
+	// string literal.
+	MessageQuote
+)
+
+// formatMessage applies mode to msg, returning a message that is safe to write
+// out according to the mode. Other than in MessageRaw mode, any single trailing
+// newline is removed before the message is processed and the result never ends
+// with a newline.
+func formatMessage(msg string, mode MessageMode, converted bool, marker string) string {
+	if mode == MessageAuto {
+		if converted {
+			mode = MessageIndent
+		} else {
+			mode = MessageRaw
+		}
+	}
+	if mode == MessageRaw {
+		return msg
+	}
+
+	msg = strings.TrimSuffix(msg, "\n")
 
-	if utc {
+	switch mode {
+	case MessageQuote:
+		return strconv.Quote(msg)
+	case MessageIndent:
+		if marker == "" {
+			marker = DefaultContinuationMarker
+		}
+		if !needsEscape(msg, true) {
+			return msg
+		}
+		return string(appendEscaped(make([]byte, 0, len(msg)+8), msg, "\n"+marker))
+	default:
+		if !needsEscape(msg, false) {
+			return msg
+		}
+		return string(appendEscaped(make([]byte, 0, len(msg)+8), msg, ""))
+	}
+}
+
+// needsEscape returns whether msg contains anything that appendEscaped would
+// change. If indent is true, tabs are allowed as they are left as-is when
+// indenting.
+func needsEscape(msg string, indent bool) bool {
+	for _, ch := range msg {
+		switch {
+		case ch == '\n':
+			return true
+		case ch == '\t':
+			if !indent {
+				return true
+			}
+		case ch == utf8.RuneError || unsafeRune(ch):
+			return true
+		}
+	}
+	return false
+}
+
+// appendEscaped appends msg to buf with control characters escaped. If
+// newline is not empty, newlines are replaced with it and tabs are left as-is;
+// otherwise newlines and tabs are escaped like any other control character.
+func appendEscaped(buf []byte, msg string, newline string) []byte {
+	for i, w := 0, 0; i < len(msg); i += w {
+		ch, width := utf8.DecodeRuneInString(msg[i:])
+		w = width
+
+		switch {
+		case ch == utf8.RuneError && width == 1:
+			buf = append(buf, `\x`...)
+			buf = append(buf, lowerHex[msg[i]>>4], lowerHex[msg[i]&0xf])
+		case ch == '\n' && newline != "":
+			buf = append(buf, newline...)
+		case ch == '\t' && newline != "":
+			buf = append(buf, '\t')
+		case ch == '\n':
+			buf = append(buf, `\n`...)
+		case ch == '\r':
+			buf = append(buf, `\r`...)
+		case ch == '\t':
+			buf = append(buf, `\t`...)
+		case unsafeRune(ch):
+			quoted := strconv.QuoteRuneToASCII(ch)
+			buf = append(buf, quoted[1:len(quoted)-1]...)
+		default:
+			buf = append(buf, msg[i:i+width]...)
+		}
+	}
+	return buf
+}
+
+const lowerHex = "0123456789abcdef"
+
+// unsafeRune returns whether ch is a control character or a character that
+// some terminals and editors treat as a line break.
+func unsafeRune(ch rune) bool {
+	return unicode.IsControl(ch) || ch == '\u2028' || ch == '\u2029'
+}
+
+func (lf LineFormat) appendTime(buf []byte, t time.Time) []byte {
+	if lf.Location != nil {
+		t = t.In(lf.Location)
+	} else if lf.UTC {
 		t = t.UTC()
 	}
 
-	// format same way as go stdlib as of 7/20/23
+	prec := lf.TimePrecision
+	if prec == PrecisionSeconds && lf.ShowMircoseconds {
+		prec = PrecisionMicroseconds
+	}
+
+	return appendTime(buf, t, lf.TimeLayout, prec)
+}
+
+// TimePrecision is the number of fractional digits of the second included in a
+// timestamp.
+type TimePrecision int
 
-	year, month, day := t.Date()
-	itoa(&buf, year, 4)
-	buf = append(buf, '/')
-	itoa(&buf, int(month), 2)
-	buf = append(buf, '/')
-	itoa(&buf, day, 2)
-	buf = append(buf, ' ')
+const (
+	// PrecisionSeconds includes no fractional digits.
+	PrecisionSeconds TimePrecision = 0
+
+	// PrecisionMilliseconds includes 3 fractional digits.
+	PrecisionMilliseconds TimePrecision = 3
+
+	// PrecisionMicroseconds includes 6 fractional digits.
+	PrecisionMicroseconds TimePrecision = 6
+
+	// PrecisionNanoseconds includes 9 fractional digits.
+	PrecisionNanoseconds TimePrecision = 9
+)
+
+// appendTime appends t to buf using the given layout. The default layout
+// ("") and time.RFC3339 are appended directly with prec fractional digits;
+// all other layouts are appended with time.AppendFormat and prec is ignored.
+func appendTime(buf []byte, t time.Time, layout string, prec TimePrecision) []byte {
+	switch layout {
+	case "":
+		// format same way as go stdlib as of 7/20/23
+		year, month, day := t.Date()
+		itoa(&buf, year, 4)
+		buf = append(buf, '/')
+		itoa(&buf, int(month), 2)
+		buf = append(buf, '/')
+		itoa(&buf, day, 2)
+		buf = append(buf, ' ')
+		buf = appendClock(buf, t, prec)
+	case time.RFC3339:
+		year, month, day := t.Date()
+		itoa(&buf, year, 4)
+		buf = append(buf, '-')
+		itoa(&buf, int(month), 2)
+		buf = append(buf, '-')
+		itoa(&buf, day, 2)
+		buf = append(buf, 'T')
+		buf = appendClock(buf, t, prec)
+
+		_, offset := t.Zone()
+		if offset == 0 {
+			buf = append(buf, 'Z')
+		} else {
+			sign := byte('+')
+			if offset < 0 {
+				sign = '-'
+				offset = -offset
+			}
+			offset /= 60
+			buf = append(buf, sign)
+			itoa(&buf, offset/60, 2)
+			buf = append(buf, ':')
+			itoa(&buf, offset%60, 2)
+		}
+	default:
+		buf = t.AppendFormat(buf, layout)
+	}
 
+	return buf
+}
+
+// appendClock appends the hh:mm:ss portion of t to buf, followed by prec
+// fractional digits of the second.
+func appendClock(buf []byte, t time.Time, prec TimePrecision) []byte {
 	hour, min, sec := t.Clock()
 	itoa(&buf, hour, 2)
 	buf = append(buf, ':')
 	itoa(&buf, min, 2)
 	buf = append(buf, ':')
 	itoa(&buf, sec, 2)
-	if micros {
+
+	if prec > 0 {
+		if prec > PrecisionNanoseconds {
+			prec = PrecisionNanoseconds
+		}
+		frac := t.Nanosecond()
+		for i := prec; i < PrecisionNanoseconds; i++ {
+			frac /= 10
+		}
 		buf = append(buf, '.')
-		itoa(&buf, t.Nanosecond()/1e3, 6)
+		itoa(&buf, frac, int(prec))
 	}
 
-	return string(buf)
+	return buf
 }
 
 // Cheap integer to fixed-width decimal ASCII. Give a negative width to avoid
@@ -0,0 +1,132 @@
+package jellog_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dekarrin/jellog"
+)
+
+func Test_LineFormat_Format_time(t *testing.T) {
+	plus5 := time.FixedZone("plus5", 5*60*60+30*60)
+	evtTime := time.Date(2026, time.March, 4, 5, 6, 7, 123456789, time.UTC)
+
+	testCases := []struct {
+		name   string
+		format jellog.LineFormat
+		expect string
+	}{
+		{
+			name:   "default layout",
+			format: jellog.LineFormat{UTC: true},
+			expect: "2026/03/04 05:06:07 INFO  a\n",
+		},
+		{
+			name:   "default layout with milliseconds",
+			format: jellog.LineFormat{UTC: true, TimePrecision: jellog.PrecisionMilliseconds},
+			expect: "2026/03/04 05:06:07.123 INFO  a\n",
+		},
+		{
+			name:   "default layout with microseconds",
+			format: jellog.LineFormat{UTC: true, TimePrecision: jellog.PrecisionMicroseconds},
+			expect: "2026/03/04 05:06:07.123456 INFO  a\n",
+		},
+		{
+			name:   "default layout with nanoseconds",
+			format: jellog.LineFormat{UTC: true, TimePrecision: jellog.PrecisionNanoseconds},
+			expect: "2026/03/04 05:06:07.123456789 INFO  a\n",
+		},
+		{
+			name:   "deprecated microseconds field",
+			format: jellog.LineFormat{UTC: true, ShowMircoseconds: true},
+			expect: "2026/03/04 05:06:07.123456 INFO  a\n",
+		},
+		{
+			name:   "precision takes priority over deprecated field",
+			format: jellog.LineFormat{UTC: true, ShowMircoseconds: true, TimePrecision: jellog.PrecisionMilliseconds},
+			expect: "2026/03/04 05:06:07.123 INFO  a\n",
+		},
+		{
+			name:   "RFC3339 in UTC",
+			format: jellog.LineFormat{UTC: true, TimeLayout: time.RFC3339},
+			expect: "2026-03-04T05:06:07Z INFO  a\n",
+		},
+		{
+			name:   "RFC3339 with offset and precision",
+			format: jellog.LineFormat{Location: plus5, TimeLayout: time.RFC3339, TimePrecision: jellog.PrecisionMilliseconds},
+			expect: "2026-03-04T10:36:07.123+05:30 INFO  a\n",
+		},
+		{
+			name:   "location takes priority over UTC",
+			format: jellog.LineFormat{UTC: true, Location: plus5},
+			expect: "2026/03/04 10:36:07 INFO  a\n",
+		},
+		{
+			name:   "other layouts ignore precision",
+			format: jellog.LineFormat{UTC: true, TimeLayout: time.Kitchen, TimePrecision: jellog.PrecisionNanoseconds},
+			expect: "5:06AM INFO  a\n",
+		},
+		{
+			name:   "omitted",
+			format: jellog.LineFormat{UTC: true, OmitTime: true, TimePrecision: jellog.PrecisionNanoseconds},
+			expect: "INFO  a\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			evt := jellog.Event[string]{Time: evtTime, Level: jellog.LvInfo, Message: "a"}
+
+			actual := string(tc.format.Format(evt))
+
+			if actual != tc.expect {
+				t.Errorf("expected %q, got %q", tc.expect, actual)
+			}
+		})
+	}
+}
+
+func Test_LineFormat_Format_timeMatchesTimeFormat(t *testing.T) {
+	west := time.FixedZone("west", -(8*60*60 + 15*60))
+	evtTime := time.Date(1999, time.December, 31, 23, 59, 59, 1000, west)
+
+	testCases := []struct {
+		name   string
+		format jellog.LineFormat
+		layout string
+	}{
+		{
+			name:   "default layout",
+			format: jellog.LineFormat{Location: west},
+			layout: "2006/01/02 15:04:05",
+		},
+		{
+			name:   "default layout with microseconds",
+			format: jellog.LineFormat{Location: west, TimePrecision: jellog.PrecisionMicroseconds},
+			layout: "2006/01/02 15:04:05.000000",
+		},
+		{
+			name:   "RFC3339",
+			format: jellog.LineFormat{Location: west, TimeLayout: time.RFC3339},
+			layout: time.RFC3339,
+		},
+		{
+			name:   "RFC3339 with nanoseconds",
+			format: jellog.LineFormat{Location: west, TimeLayout: time.RFC3339, TimePrecision: jellog.PrecisionNanoseconds},
+			layout: "2006-01-02T15:04:05.000000000Z07:00",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			evt := jellog.Event[string]{Time: evtTime, Level: jellog.LvInfo, Message: "a"}
+			expect := evtTime.Format(tc.layout) + " INFO  a\n"
+
+			actual := string(tc.format.Format(evt))
+
+			if actual != expect {
+				t.Errorf("expected %q, got %q", expect, actual)
+			}
+		})
+	}
+}
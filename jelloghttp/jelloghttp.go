@@ -0,0 +1,85 @@
+// Package jelloghttp provides net/http integration for jellog.
+package jelloghttp
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/dekarrin/jellog"
+)
+
+// DefaultForceLevelHeader is the request header checked by ForceLevel when no
+// other header is configured.
+const DefaultForceLevelHeader = "X-Log-Level"
+
+// ForceLevelOptions is used to control the behavior of the middleware returned
+// by ForceLevel.
+type ForceLevelOptions struct {
+	// Header is the name of the request header that requests a forced level.
+	// If not set, DefaultForceLevelHeader is used.
+	//
+	// If the header's value is the name or alias of a registered level, that
+	// level is forced. Any other non-empty value forces Level.
+	Header string
+
+	// Level is the level that is forced when the header is present but does
+	// not name a level. If not set, jellog.LvTrace is used.
+	Level jellog.Level
+
+	// Allow is called for each request that has the header, and the level is
+	// only forced if it returns true. It should check that the request comes
+	// from someone trusted to increase logging verbosity, as any client that
+	// can force a level can greatly multiply the volume of logs written. If
+	// not set, no request is allowed and the header is ignored; use AllowAll
+	// to allow every request, such as for a server that is only reachable by
+	// trusted clients.
+	Allow func(r *http.Request) bool
+}
+
+// AllowAll allows every request to force a level. It can be given as the Allow
+// field of ForceLevelOptions for servers that are not reachable by untrusted
+// clients.
+func AllowAll(r *http.Request) bool {
+	return true
+}
+
+// ForceLevel returns middleware that forces the logging level of requests that
+// carry a configured header. The context of each such request is replaced with
+// one created by jellog.WithForcedLevel, so events logged with it using the
+// context-aware logging methods, such as InfoCtx, are sent to every Handler
+// that has HonorForcedLevel set regardless of the Handler's own minimum level.
+//
+// Only requests that the Allow option approves may force a level. Because Allow
+// is not set by default, the returned middleware does nothing until it is.
+//
+// To use the default set of ForceLevelOptions, pass nil for opts.
+func ForceLevel(opts *ForceLevelOptions) func(http.Handler) http.Handler {
+	var o ForceLevelOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.Header == "" {
+		o.Header = DefaultForceLevelHeader
+	}
+	if o.Level.Name == "" && o.Level.Severity == 0 {
+		o.Level = jellog.LvTrace
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			val := strings.TrimSpace(r.Header.Get(o.Header))
+			if val == "" || o.Allow == nil || !o.Allow(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			lv, ok := jellog.LevelByName(val)
+			if !ok {
+				lv = o.Level
+			}
+
+			ctx := jellog.WithForcedLevel(r.Context(), lv)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
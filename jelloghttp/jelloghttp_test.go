@@ -0,0 +1,93 @@
+package jelloghttp_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dekarrin/jellog"
+	"github.com/dekarrin/jellog/jelloghttp"
+)
+
+func Test_ForceLevel(t *testing.T) {
+	denyAll := func(r *http.Request) bool { return false }
+
+	testCases := []struct {
+		name        string
+		opts        *jelloghttp.ForceLevelOptions
+		header      string
+		value       string
+		expectForce bool
+		expectLevel jellog.Level
+	}{
+		{
+			name:   "nil options ignore the header",
+			opts:   nil,
+			header: jelloghttp.DefaultForceLevelHeader,
+			value:  "trace",
+		},
+		{
+			name:   "unset Allow ignores the header",
+			opts:   &jelloghttp.ForceLevelOptions{Level: jellog.LvDebug},
+			header: jelloghttp.DefaultForceLevelHeader,
+			value:  "trace",
+		},
+		{
+			name:   "Allow returning false ignores the header",
+			opts:   &jelloghttp.ForceLevelOptions{Allow: denyAll},
+			header: jelloghttp.DefaultForceLevelHeader,
+			value:  "trace",
+		},
+		{
+			name:   "allowed request without the header is not forced",
+			opts:   &jelloghttp.ForceLevelOptions{Allow: jelloghttp.AllowAll},
+			header: "X-Something-Else",
+			value:  "trace",
+		},
+		{
+			name:        "allowed request forces named level",
+			opts:        &jelloghttp.ForceLevelOptions{Allow: jelloghttp.AllowAll},
+			header:      jelloghttp.DefaultForceLevelHeader,
+			value:       "debug",
+			expectForce: true,
+			expectLevel: jellog.LvDebug,
+		},
+		{
+			name:        "allowed request with unknown value forces default level",
+			opts:        &jelloghttp.ForceLevelOptions{Allow: jelloghttp.AllowAll},
+			header:      jelloghttp.DefaultForceLevelHeader,
+			value:       "yes please",
+			expectForce: true,
+			expectLevel: jellog.LvTrace,
+		},
+		{
+			name:        "custom header and level",
+			opts:        &jelloghttp.ForceLevelOptions{Header: "X-Debug", Level: jellog.LvInfo, Allow: jelloghttp.AllowAll},
+			header:      "X-Debug",
+			value:       "1",
+			expectForce: true,
+			expectLevel: jellog.LvInfo,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var forced bool
+			var lv jellog.Level
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				lv, forced = jellog.ForcedLevel(r.Context())
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(tc.header, tc.value)
+			jelloghttp.ForceLevel(tc.opts)(next).ServeHTTP(httptest.NewRecorder(), req)
+
+			if forced != tc.expectForce {
+				t.Fatalf("expected forced to be %v, got %v", tc.expectForce, forced)
+			}
+			if forced && lv.Severity != tc.expectLevel.Severity {
+				t.Errorf("expected forced level %v, got %v", tc.expectLevel, lv)
+			}
+		})
+	}
+}
@@ -0,0 +1,308 @@
+// Package jellogtest provides utilities for testing code that logs with jellog.
+//
+// A Recorder is a Handler that keeps every event it is given so that tests can
+// check what was logged without writing to and reading back from a file:
+//
+//	rec := jellogtest.NewRecorder[string](nil)
+//	lg := jellog.New(jellog.Defaults[string]().WithHandler(jellog.LvAll, rec))
+//
+//	doSomething(lg)
+//
+//	jellogtest.AssertHasEvent(t, rec, jellog.LvWarn, "retrying")
+//
+// A TBHandler sends log output to a testing.TB so that it is shown alongside
+// the test that produced it, and only when that test fails or is run with -v.
+package jellogtest
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/dekarrin/jellog"
+)
+
+// Matcher returns whether an event matches some condition. Matchers are used
+// to select events from a Recorder.
+type Matcher[E any] func(evt jellog.Event[E]) bool
+
+// AtLevel returns a Matcher that matches events whose level has the same
+// severity as lv.
+func AtLevel[E any](lv jellog.Level) Matcher[E] {
+	return func(evt jellog.Event[E]) bool {
+		return evt.Level.Severity == lv.Severity
+	}
+}
+
+// InComponent returns a Matcher that matches events whose component is c or
+// is a sub-component of c. For example, InComponent("server") matches events
+// with the component "server" and "server.http" but not "servers".
+func InComponent[E any](c string) Matcher[E] {
+	return func(evt jellog.Event[E]) bool {
+		return evt.Component == c || strings.HasPrefix(evt.Component, c+".")
+	}
+}
+
+// Contains returns a Matcher that matches events whose message contains sub.
+// Messages that are not strings are converted to one with fmt.Sprint before
+// being checked.
+func Contains[E any](sub string) Matcher[E] {
+	return func(evt jellog.Event[E]) bool {
+		return strings.Contains(messageText(evt), sub)
+	}
+}
+
+// HasAttr returns a Matcher that matches events that have an Attr with the
+// given key.
+func HasAttr[E any](key string) Matcher[E] {
+	return func(evt jellog.Event[E]) bool {
+		for _, a := range evt.Attrs {
+			if a.Key == key {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// HasError returns a Matcher that matches events that were logged for an error
+// that is target or wraps it, as determined by errors.Is. If target is nil,
+// events logged for any error are matched.
+func HasError[E any](target error) Matcher[E] {
+	return func(evt jellog.Event[E]) bool {
+		if target == nil {
+			return evt.Err != nil
+		}
+		return errors.Is(evt.Err, target)
+	}
+}
+
+// All returns a Matcher that matches events that are matched by every one of
+// ms. If ms is empty, every event is matched.
+func All[E any](ms ...Matcher[E]) Matcher[E] {
+	return func(evt jellog.Event[E]) bool {
+		for _, m := range ms {
+			if !m(evt) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Recorder is a Handler that records every event given to it for later
+// inspection. It applies no formatting; events are kept exactly as they are
+// received, except that the Recorder's component, if it is configured with
+// one, is chained onto the event's component just as it would be by any other
+// Handler.
+//
+// A Recorder is safe to use from multiple goroutines. The zero-value of a
+// Recorder is ready to use with default options; to set the options, use
+// NewRecorder.
+type Recorder[E any] struct {
+	opts jellog.HandlerOptions[E]
+
+	mtx    sync.Mutex
+	events []jellog.Event[E]
+	breaks int
+}
+
+// NewRecorder creates a new Recorder.
+//
+// To use the default set of HandlerOptions, pass nil for opts.
+func NewRecorder[E any](opts *jellog.HandlerOptions[E]) *Recorder[E] {
+	if opts == nil {
+		opts = &jellog.HandlerOptions[E]{}
+	}
+
+	return &Recorder[E]{opts: *opts}
+}
+
+// HandlerOptions returns the options that the Recorder is configured with.
+// Modifying the returned struct has no effect on rec.
+func (rec *Recorder[E]) HandlerOptions() jellog.HandlerOptions[E] {
+	return rec.opts
+}
+
+// InsertBreak counts a break. The number of breaks inserted can be retrieved
+// with Breaks.
+func (rec *Recorder[E]) InsertBreak() error {
+	rec.mtx.Lock()
+	defer rec.mtx.Unlock()
+
+	rec.breaks++
+	return nil
+}
+
+// Output records a log event. It always returns nil.
+//
+// The calldepth argument is used for recovering the program counter. It should
+// be supplied with the number of levels into the jellog package that the caller
+// has reached, with the externally called function counting as 1.
+func (rec *Recorder[E]) Output(calldepth int, evt jellog.Event[E]) error {
+	// chain our component with the event's component if we have one
+	if rec.opts.Component != "" {
+		if evt.Component != "" {
+			evt.Component += "."
+		}
+		evt.Component += rec.opts.Component
+	}
+
+	rec.mtx.Lock()
+	defer rec.mtx.Unlock()
+
+	rec.events = append(rec.events, evt)
+	return nil
+}
+
+// Events returns all events recorded so far, in the order they were received.
+// The returned slice is a copy and may be freely modified.
+func (rec *Recorder[E]) Events() []jellog.Event[E] {
+	rec.mtx.Lock()
+	defer rec.mtx.Unlock()
+
+	return append([]jellog.Event[E]{}, rec.events...)
+}
+
+// Len returns the number of events recorded so far.
+func (rec *Recorder[E]) Len() int {
+	rec.mtx.Lock()
+	defer rec.mtx.Unlock()
+
+	return len(rec.events)
+}
+
+// Breaks returns the number of times InsertBreak has been called.
+func (rec *Recorder[E]) Breaks() int {
+	rec.mtx.Lock()
+	defer rec.mtx.Unlock()
+
+	return rec.breaks
+}
+
+// Reset discards all recorded events and breaks.
+func (rec *Recorder[E]) Reset() {
+	rec.mtx.Lock()
+	defer rec.mtx.Unlock()
+
+	rec.events = nil
+	rec.breaks = 0
+}
+
+// Filter returns the recorded events that are matched by m, in the order they
+// were received.
+func (rec *Recorder[E]) Filter(m Matcher[E]) []jellog.Event[E] {
+	rec.mtx.Lock()
+	defer rec.mtx.Unlock()
+
+	var matched []jellog.Event[E]
+	for _, evt := range rec.events {
+		if m(evt) {
+			matched = append(matched, evt)
+		}
+	}
+	return matched
+}
+
+// Count returns the number of recorded events that are matched by m.
+func (rec *Recorder[E]) Count(m Matcher[E]) int {
+	rec.mtx.Lock()
+	defer rec.mtx.Unlock()
+
+	n := 0
+	for _, evt := range rec.events {
+		if m(evt) {
+			n++
+		}
+	}
+	return n
+}
+
+// CountLevel returns the number of recorded events at level lv.
+func (rec *Recorder[E]) CountLevel(lv jellog.Level) int {
+	return rec.Count(AtLevel[E](lv))
+}
+
+// CountComponent returns the number of recorded events in component c or any
+// of its sub-components.
+func (rec *Recorder[E]) CountComponent(c string) int {
+	return rec.Count(InComponent[E](c))
+}
+
+// HasEvent returns whether an event at level lv whose message contains sub has
+// been recorded.
+func (rec *Recorder[E]) HasEvent(lv jellog.Level, sub string) bool {
+	return rec.Count(All(AtLevel[E](lv), Contains[E](sub))) > 0
+}
+
+// AssertHasEvent fails the test if rec has not recorded an event at level lv
+// whose message contains sub. The events that were recorded are listed in the
+// failure message.
+func AssertHasEvent[E any](t testing.TB, rec *Recorder[E], lv jellog.Level, sub string) {
+	t.Helper()
+	if !rec.HasEvent(lv, sub) {
+		t.Errorf("no %s event containing %q was logged; got:\n%s", lv, sub, describeEvents(rec.Events()))
+	}
+}
+
+// AssertNoEvent fails the test if rec has recorded any event that is matched
+// by m.
+func AssertNoEvent[E any](t testing.TB, rec *Recorder[E], m Matcher[E]) {
+	t.Helper()
+	if matched := rec.Filter(m); len(matched) > 0 {
+		t.Errorf("expected no matching events to be logged; got:\n%s", describeEvents(matched))
+	}
+}
+
+// AssertCount fails the test if the number of events recorded by rec that are
+// matched by m is not n.
+func AssertCount[E any](t testing.TB, rec *Recorder[E], m Matcher[E], n int) {
+	t.Helper()
+	if matched := rec.Filter(m); len(matched) != n {
+		t.Errorf("expected %d matching events to be logged; got %d:\n%s", n, len(matched), describeEvents(matched))
+	}
+}
+
+// describeEvents gives a line for each event in evts, for use in failure
+// messages.
+func describeEvents[E any](evts []jellog.Event[E]) string {
+	if len(evts) == 0 {
+		return "\t(none)"
+	}
+	var sb strings.Builder
+	for i, evt := range evts {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		sb.WriteByte('\t')
+		sb.WriteString(describeEvent(evt))
+	}
+	return sb.String()
+}
+
+// describeEvent gives a single-line description of evt containing its level,
+// component, message, and Attrs.
+func describeEvent[E any](evt jellog.Event[E]) string {
+	var sb strings.Builder
+	sb.WriteString(evt.Level.String())
+	if evt.Component != "" {
+		sb.WriteString(" (")
+		sb.WriteString(evt.Component)
+		sb.WriteByte(')')
+	}
+	sb.WriteByte(' ')
+	sb.WriteString(fmt.Sprintf("%q", messageText(evt)))
+	for _, a := range evt.Attrs {
+		sb.WriteString(fmt.Sprintf(" %s=%v", a.Key, a.Value))
+	}
+	return sb.String()
+}
+
+func messageText[E any](evt jellog.Event[E]) string {
+	if s, ok := any(evt.Message).(string); ok {
+		return s
+	}
+	return fmt.Sprint(evt.Message)
+}
@@ -0,0 +1,240 @@
+package jellogtest_test
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"testing"
+
+	"github.com/dekarrin/jellog"
+	"github.com/dekarrin/jellog/jellogtest"
+)
+
+func Test_Matchers(t *testing.T) {
+	wrapped := fmt.Errorf("read config: %w", fs.ErrNotExist)
+
+	testCases := []struct {
+		name   string
+		m      jellogtest.Matcher[string]
+		evt    jellog.Event[string]
+		expect bool
+	}{
+		{name: "AtLevel same severity", m: jellogtest.AtLevel[string](jellog.LvWarn), evt: jellog.Event[string]{Level: jellog.LvWarn}, expect: true},
+		{name: "AtLevel different severity", m: jellogtest.AtLevel[string](jellog.LvWarn), evt: jellog.Event[string]{Level: jellog.LvError}},
+		{name: "InComponent exact", m: jellogtest.InComponent[string]("server"), evt: jellog.Event[string]{Component: "server"}, expect: true},
+		{name: "InComponent sub-component", m: jellogtest.InComponent[string]("server"), evt: jellog.Event[string]{Component: "server.http"}, expect: true},
+		{name: "InComponent shared prefix", m: jellogtest.InComponent[string]("server"), evt: jellog.Event[string]{Component: "servers"}},
+		{name: "InComponent none", m: jellogtest.InComponent[string]("server"), evt: jellog.Event[string]{}},
+		{name: "Contains substring", m: jellogtest.Contains[string]("retry"), evt: jellog.Event[string]{Message: "retrying in 5s"}, expect: true},
+		{name: "Contains missing", m: jellogtest.Contains[string]("retry"), evt: jellog.Event[string]{Message: "giving up"}},
+		{name: "HasAttr present", m: jellogtest.HasAttr[string]("user"), evt: jellog.Event[string]{Attrs: []jellog.Attr{{Key: "id"}, {Key: "user"}}}, expect: true},
+		{name: "HasAttr absent", m: jellogtest.HasAttr[string]("user"), evt: jellog.Event[string]{Attrs: []jellog.Attr{{Key: "id"}}}},
+		{name: "HasError wrapped target", m: jellogtest.HasError[string](fs.ErrNotExist), evt: jellog.Event[string]{Err: wrapped}, expect: true},
+		{name: "HasError other target", m: jellogtest.HasError[string](fs.ErrPermission), evt: jellog.Event[string]{Err: wrapped}},
+		{name: "HasError nil target any error", m: jellogtest.HasError[string](nil), evt: jellog.Event[string]{Err: wrapped}, expect: true},
+		{name: "HasError nil target no error", m: jellogtest.HasError[string](nil), evt: jellog.Event[string]{}},
+		{name: "All empty", m: jellogtest.All[string](), evt: jellog.Event[string]{}, expect: true},
+		{
+			name:   "All every matcher matches",
+			m:      jellogtest.All(jellogtest.AtLevel[string](jellog.LvInfo), jellogtest.Contains[string]("ok")),
+			evt:    jellog.Event[string]{Level: jellog.LvInfo, Message: "ok"},
+			expect: true,
+		},
+		{
+			name: "All one matcher fails",
+			m:    jellogtest.All(jellogtest.AtLevel[string](jellog.LvInfo), jellogtest.Contains[string]("ok")),
+			evt:  jellog.Event[string]{Level: jellog.LvDebug, Message: "ok"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := tc.m(tc.evt); actual != tc.expect {
+				t.Errorf("expected %v, got %v", tc.expect, actual)
+			}
+		})
+	}
+}
+
+func Test_Contains_nonString(t *testing.T) {
+	m := jellogtest.Contains[int]("42")
+	if !m(jellog.Event[int]{Message: 1425}) {
+		t.Errorf("expected message 1425 to contain %q", "42")
+	}
+}
+
+func Test_Recorder(t *testing.T) {
+	testCases := []struct {
+		name      string
+		opts      *jellog.HandlerOptions[string]
+		log       func(lg jellog.Logger[string])
+		expectLen int
+		expectBrk int
+		check     func(t *testing.T, rec *jellogtest.Recorder[string])
+	}{
+		{
+			name:      "nothing logged",
+			log:       func(lg jellog.Logger[string]) {},
+			expectLen: 0,
+		},
+		{
+			name: "events are kept in order",
+			log: func(lg jellog.Logger[string]) {
+				lg.Info("first")
+				lg.Warn("second")
+				lg.Info("third")
+			},
+			expectLen: 3,
+			check: func(t *testing.T, rec *jellogtest.Recorder[string]) {
+				evts := rec.Events()
+				for i, expect := range []string{"first", "second", "third"} {
+					if evts[i].Message != expect {
+						t.Errorf("event %d: expected message %q, got %q", i, expect, evts[i].Message)
+					}
+				}
+				if n := rec.CountLevel(jellog.LvInfo); n != 2 {
+					t.Errorf("expected 2 INFO events, got %d", n)
+				}
+				if !rec.HasEvent(jellog.LvWarn, "sec") {
+					t.Errorf("expected WARN event containing %q", "sec")
+				}
+				if rec.HasEvent(jellog.LvInfo, "sec") {
+					t.Errorf("expected no INFO event containing %q", "sec")
+				}
+			},
+		},
+		{
+			name: "breaks are counted",
+			log: func(lg jellog.Logger[string]) {
+				lg.Info("one")
+				lg.InsertBreak(jellog.LvInfo)
+				lg.InsertBreak(jellog.LvInfo)
+			},
+			expectLen: 1,
+			expectBrk: 2,
+		},
+		{
+			name: "component is chained",
+			opts: &jellog.HandlerOptions[string]{Component: "app"},
+			log: func(lg jellog.Logger[string]) {
+				lg.Info("no component")
+				lg.Copy(jellog.Options[string]{}.WithComponent("db")).Info("with component")
+			},
+			expectLen: 2,
+			check: func(t *testing.T, rec *jellogtest.Recorder[string]) {
+				if n := rec.CountComponent("app"); n != 1 {
+					t.Errorf("expected 1 event in component app, got %d", n)
+				}
+				if n := rec.CountComponent("db"); n != 1 {
+					t.Errorf("expected 1 event in component db, got %d", n)
+				}
+				evts := rec.Filter(jellogtest.Contains[string]("with"))
+				if len(evts) != 1 || evts[0].Component != "db.app" {
+					t.Errorf("expected 1 event in component db.app, got %v", evts)
+				}
+			},
+		},
+		{
+			name: "errors can be matched",
+			log: func(lg jellog.Logger[string]) {
+				lg.Info("fine")
+				lg.Err(fmt.Errorf("open: %w", fs.ErrNotExist), "loading")
+			},
+			expectLen: 2,
+			check: func(t *testing.T, rec *jellogtest.Recorder[string]) {
+				jellogtest.AssertCount(t, rec, jellogtest.HasError[string](fs.ErrNotExist), 1)
+				jellogtest.AssertCount(t, rec, jellogtest.HasError[string](nil), 1)
+				jellogtest.AssertNoEvent(t, rec, jellogtest.HasError[string](fs.ErrExist))
+				jellogtest.AssertHasEvent(t, rec, jellog.LvError, "loading: open")
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := jellogtest.NewRecorder(tc.opts)
+			lg := jellog.New(jellog.Defaults[string]())
+			lg.AddHandler(jellog.LvTrace, rec)
+
+			tc.log(lg)
+
+			if n := rec.Len(); n != tc.expectLen {
+				t.Errorf("expected %d events, got %d", tc.expectLen, n)
+			}
+			if n := rec.Breaks(); n != tc.expectBrk {
+				t.Errorf("expected %d breaks, got %d", tc.expectBrk, n)
+			}
+			if tc.check != nil {
+				tc.check(t, rec)
+			}
+
+			rec.Reset()
+			if rec.Len() != 0 || rec.Breaks() != 0 {
+				t.Errorf("expected Reset to discard everything, got %d events and %d breaks", rec.Len(), rec.Breaks())
+			}
+		})
+	}
+}
+
+// failureTB is a testing.TB that only records whether a test would have
+// failed.
+type failureTB struct {
+	testing.TB
+	failed bool
+}
+
+func (ft *failureTB) Helper() {}
+
+func (ft *failureTB) Errorf(format string, args ...any) {
+	ft.failed = true
+}
+
+func Test_Asserts(t *testing.T) {
+	rec := jellogtest.NewRecorder[string](nil)
+	rec.Output(1, jellog.Event[string]{Level: jellog.LvWarn, Message: "disk almost full"})
+	rec.Output(1, jellog.Event[string]{Level: jellog.LvInfo, Message: "request done", Err: errors.New("x")})
+
+	testCases := []struct {
+		name       string
+		assert     func(tb testing.TB)
+		expectFail bool
+	}{
+		{
+			name:   "AssertHasEvent present",
+			assert: func(tb testing.TB) { jellogtest.AssertHasEvent(tb, rec, jellog.LvWarn, "disk") },
+		},
+		{
+			name:       "AssertHasEvent wrong level",
+			assert:     func(tb testing.TB) { jellogtest.AssertHasEvent(tb, rec, jellog.LvError, "disk") },
+			expectFail: true,
+		},
+		{
+			name:   "AssertNoEvent none match",
+			assert: func(tb testing.TB) { jellogtest.AssertNoEvent(tb, rec, jellogtest.AtLevel[string](jellog.LvFatal)) },
+		},
+		{
+			name:       "AssertNoEvent some match",
+			assert:     func(tb testing.TB) { jellogtest.AssertNoEvent(tb, rec, jellogtest.HasError[string](nil)) },
+			expectFail: true,
+		},
+		{
+			name:   "AssertCount right count",
+			assert: func(tb testing.TB) { jellogtest.AssertCount(tb, rec, jellogtest.All[string](), 2) },
+		},
+		{
+			name:       "AssertCount wrong count",
+			assert:     func(tb testing.TB) { jellogtest.AssertCount(tb, rec, jellogtest.All[string](), 1) },
+			expectFail: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ft := &failureTB{TB: t}
+			tc.assert(ft)
+			if ft.failed != tc.expectFail {
+				t.Errorf("expected failure to be %v, got %v", tc.expectFail, ft.failed)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package jellogtest
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/dekarrin/jellog"
+)
+
+// TBHandler is a Handler that writes log events to a testing.TB with its Log
+// method. This attributes the output to the test that is running, and the
+// testing package only shows it if that test fails or if tests are run with
+// -v, so it keeps the output of passing tests quiet while still making the
+// logs of a failed test available.
+//
+// Because the testing package prefixes each line with the location it was
+// logged from, which for a TBHandler is always inside jellog, the location the
+// event was actually logged from is included in the output when no Formatter is
+// configured.
+//
+// Once the test that a TBHandler was created for has completed, further events
+// are silently discarded instead of causing the testing package to panic, so
+// it is safe for goroutines that outlive the test to keep logging.
+//
+// A TBHandler is safe to use from multiple goroutines. It should be created
+// with NewTBHandler.
+type TBHandler[E any] struct {
+	t    testing.TB
+	opts jellog.HandlerOptions[E]
+
+	mtx  sync.Mutex
+	done bool
+}
+
+// NewTBHandler creates a TBHandler that writes to t. If opts.Formatter is set,
+// it is used to create the text of each event, with any trailing newline
+// removed; otherwise, a short line with the level, component, message, source
+// location, and Attrs of the event is used.
+//
+// To use the default set of HandlerOptions, pass nil for opts.
+func NewTBHandler[E any](t testing.TB, opts *jellog.HandlerOptions[E]) *TBHandler[E] {
+	if opts == nil {
+		opts = &jellog.HandlerOptions[E]{}
+	}
+
+	th := &TBHandler[E]{t: t, opts: *opts}
+	t.Cleanup(func() {
+		th.mtx.Lock()
+		defer th.mtx.Unlock()
+		th.done = true
+	})
+	return th
+}
+
+// NewTestLogger returns a Logger that sends events of every level to t using a
+// TBHandler with default options.
+func NewTestLogger(t testing.TB) jellog.Logger[string] {
+	return jellog.New(jellog.Defaults[string]().WithHandler(jellog.LvAll, NewTBHandler[string](t, nil)))
+}
+
+// HandlerOptions returns the options that the TBHandler is configured with.
+// Modifying the returned struct has no effect on th.
+func (th *TBHandler[E]) HandlerOptions() jellog.HandlerOptions[E] {
+	return th.opts
+}
+
+// InsertBreak does nothing and returns nil. Each call to the Log method of a
+// testing.TB is already shown on its own line.
+func (th *TBHandler[E]) InsertBreak() error {
+	return nil
+}
+
+// Output writes a log event to the testing.TB. It always returns nil.
+//
+// The calldepth argument is used for recovering the program counter. It should
+// be supplied with the number of levels into the jellog package that the caller
+// has reached, with the externally called function counting as 1.
+func (th *TBHandler[E]) Output(calldepth int, evt jellog.Event[E]) error {
+	// chain our component with the event's component if we have one
+	if th.opts.Component != "" {
+		if evt.Component != "" {
+			evt.Component += "."
+		}
+		evt.Component += th.opts.Component
+	}
+
+	var text string
+	if th.opts.Formatter != nil {
+		text = strings.TrimSuffix(string(th.opts.Formatter.Format(evt)), "\n")
+	} else {
+		text = describeEvent(evt)
+		if file, line, _, ok := evt.Caller(); ok {
+			text = fmt.Sprintf("%s:%d: %s", filepath.Base(file), line, text)
+		}
+	}
+
+	th.mtx.Lock()
+	defer th.mtx.Unlock()
+
+	if !th.done {
+		th.t.Log(text)
+	}
+	return nil
+}
@@ -29,8 +29,9 @@
 package jellog
 
 import (
+	"context"
 	"fmt"
-	"os"
+	"runtime"
 	"time"
 )
 
@@ -51,7 +52,123 @@ type Event[E any] struct {
 	Time      time.Time
 	Level     Level
 
+	// PC is the program counter of the call site that logged the event. It is
+	// filled in by Logger.Output if it is not already set, and is 0 if the call
+	// site is unknown.
+	PC uintptr
+
+	// Stack is the stack trace of the goroutine that logged the event,
+	// starting at the call site given by PC, or nil if no trace was captured.
+	// A Logger fills it in for events at or above its StackTraceLevel. Text
+	// Formatters such as LineFormat print it after the message; structured
+	// Formatters can emit it as an array.
+	Stack []Frame
+
+	// Err is the error that the event was logged for by one of the error
+	// logging methods, such as Logger.Err, or nil if it was not logged for an
+	// error. A RedactProcessor replaces it with an error that has a redacted
+	// message but still wraps the original.
+	Err error
+
+	// Causes is the chain of Err, starting with Err itself and followed by
+	// every error that it wraps, as found by errors.Unwrap or by the method
+	// used by errors.Join. Text Formatters such as LineFormat print the
+	// wrapped errors after the message; structured Formatters can emit it as
+	// an array.
+	Causes []Cause
+
+	// Attrs is additional structured data associated with the event. Handlers
+	// that write to structured destinations may record these as individual
+	// fields; text-based Formatters are free to ignore them.
+	Attrs []Attr
+
+	// Converted is whether Message was created by a Logger's Converter from a
+	// value that was not already of the logged type. Such messages often
+	// contain data from outside the program, so Formatters may choose to treat
+	// them as untrusted.
+	Converted bool
+
 	Message E
+
+	// ctx is the context the event was logged with, if any.
+	ctx context.Context
+
+	// callers is the stack of the goroutine that logged the event, as program
+	// counters starting at PC. It is recorded before the event is handed to
+	// another goroutine, so that a Logger reached from there can still fill in
+	// Stack with the stack of the caller rather than its own.
+	callers []uintptr
+}
+
+// Context returns the context that the event was logged with by one of the
+// context-aware logging methods, such as InfoCtx. If the event was not logged
+// with a context, context.Background() is returned.
+func (evt Event[E]) Context() context.Context {
+	if evt.ctx == nil {
+		return context.Background()
+	}
+	return evt.ctx
+}
+
+// WithContext returns a copy of evt that has the given context.
+func (evt Event[E]) WithContext(ctx context.Context) Event[E] {
+	evt.ctx = ctx
+	return evt
+}
+
+// Attr is a single key-value pair of structured data attached to an Event.
+type Attr struct {
+	Key   string
+	Value any
+}
+
+// LogValuer is implemented by values that should be represented differently in
+// log output than they otherwise would be. When a LogValuer is converted to a
+// message by the default Converter or is the value of an Attr that is written
+// out by one of the built-in Formatters or Handlers, the result of calling its
+// LogValue method is used in its place.
+type LogValuer interface {
+	LogValue() any
+}
+
+// resolveLogValue returns the value to log in place of v. If v is a LogValuer,
+// its LogValue is used, repeatedly if that is itself a LogValuer.
+func resolveLogValue(v any) any {
+	// bounded in case a LogValuer returns itself
+	for i := 0; i < 100; i++ {
+		lv, ok := v.(LogValuer)
+		if !ok {
+			return v
+		}
+		v = lv.LogValue()
+	}
+	return v
+}
+
+// Caller returns the source file, line, and fully-qualified function name of
+// the call site that logged the event. If the call site is not known, ok will
+// be false.
+func (evt Event[E]) Caller() (file string, line int, function string, ok bool) {
+	if evt.PC == 0 {
+		return "", 0, "", false
+	}
+	frames := runtime.CallersFrames([]uintptr{evt.PC})
+	f, _ := frames.Next()
+	if f.File == "" {
+		return "", 0, "", false
+	}
+	return f.File, f.Line, f.Function, true
+}
+
+// callerPC returns the program counter of the function calldepth levels above
+// the function calling callerPC, with the caller of callerPC counting as 0.
+func callerPC(calldepth int) uintptr {
+	var pcs [1]uintptr
+	// skip runtime.Callers and callerPC itself
+	if runtime.Callers(calldepth+2, pcs[:]) < 1 {
+		return 0
+	}
+	return pcs[0]
 }
 
 // Handler outputs log messages. A Handler will generally hold all info needed
@@ -87,6 +204,14 @@ type Handler[E any] interface {
 	InsertBreak() error
 }
 
+// Default returns the default "standard" Logger used by the package-level
+// logging functions. The returned Logger shares its Handlers with the default
+// logger, so adding a Handler to it affects the package-level functions as
+// well.
+func Default() Logger[string] {
+	return std
+}
+
 // InsertBreak inserts a disambiguating separator in the default logger. Before
 // inserting it, the Logger may check to see if it is necessary, and it may
 // choose to omit outputting it if not needed.
@@ -105,8 +230,7 @@ func InsertBreak(lv Level) error {
 //
 // This function is included for compatibility with the built-in log package.
 func Print(v ...any) {
-	evt := std.CreateEvent(LvInfo, fmt.Sprint(v...))
-	std.Output(2, evt)
+	std.log(2, LvInfo, fmt.Sprint(v...))
 }
 
 // Printf logs a message using the default logger at severity level INFO. It is
@@ -114,8 +238,7 @@ func Print(v ...any) {
 //
 // This function is included for compatibility with the built-in log package.
 func Printf(format string, v ...any) {
-	evt := std.CreateEvent(LvInfo, fmt.Sprintf(format, v...))
-	std.Output(2, evt)
+	std.log(2, LvInfo, fmt.Sprintf(format, v...))
 }
 
 // Println logs a message using the default logger at severity level INFO.
@@ -123,41 +246,34 @@ func Printf(format string, v ...any) {
 //
 // This function is included for compatibility with the built-in log package.
 func Println(v ...any) {
-	evt := std.CreateEvent(LvInfo, fmt.Sprintln(v...))
-	std.Output(2, evt)
+	std.log(2, LvInfo, fmt.Sprintln(v...))
 }
 
 // Fatal logs a message using the default logger at severity level FATAL and
-// then immediately calls os.Exit(1). Arguments are handled in the manner of
-// fmt.Print.
+// then exits the program with status code 1 as described in Logger.Exit.
+// Arguments are handled in the manner of fmt.Print.
 //
 // This function is included for compatibility with the built-in log package.
 func Fatal(v ...any) {
-	evt := std.CreateEvent(LvFatal, fmt.Sprint(v...))
-	std.Output(2, evt)
-	os.Exit(1)
+	std.fatal(2, 1, fmt.Sprint(v...))
 }
 
 // Fatalf logs a message using the default logger at severity level FATAL and
-// then immediately calls os.Exit(1). Arguments are handled in the manner of
-// fmt.Printf.
+// then exits the program with status code 1 as described in Logger.Exit.
+// Arguments are handled in the manner of fmt.Printf.
 //
 // This function is included for compatibility with the built-in log package.
 func Fatalf(format string, v ...any) {
-	evt := std.CreateEvent(LvFatal, fmt.Sprintf(format, v...))
-	std.Output(2, evt)
-	os.Exit(1)
+	std.fatal(2, 1, fmt.Sprintf(format, v...))
 }
 
 // Fatalln logs a message using the default logger at severity level FATAL and
-// then immediately calls os.Exit(1). Arguments are handled in the manner of
-// fmt.Println.
+// then exits the program with status code 1 as described in Logger.Exit.
+// Arguments are handled in the manner of fmt.Println.
 //
 // This function is included for compatibility with the built-in log package.
 func Fatalln(v ...any) {
-	evt := std.CreateEvent(LvFatal, fmt.Sprintln(v...))
-	std.Output(2, evt)
-	os.Exit(1)
+	std.fatal(2, 1, fmt.Sprintln(v...))
 }
 
 // Panic logs a message using the default logger at severity level FATAL and
@@ -166,10 +282,7 @@ func Fatalln(v ...any) {
 //
 // This function is included for compatibility with the built-in log package.
 func Panic(v ...any) {
-	msg := fmt.Sprint(v...)
-	evt := std.CreateEvent(LvFatal, msg)
-	std.Output(2, evt)
-	panic(msg)
+	std.panic(2, fmt.Sprint(v...))
 }
 
 // Panicf logs a message using the default logger at severity level FATAL and
@@ -178,10 +291,7 @@ func Panic(v ...any) {
 //
 // This function is included for compatibility with the built-in log package.
 func Panicf(format string, v ...any) {
-	msg := fmt.Sprintf(format, v...)
-	evt := std.CreateEvent(LvFatal, msg)
-	std.Output(2, evt)
-	panic(msg)
+	std.panic(2, fmt.Sprintf(format, v...))
 }
 
 // Panicln logs a message using the default logger at severity level FATAL and
@@ -190,86 +300,107 @@ func Panicf(format string, v ...any) {
 //
 // This function is included for compatibility with the built-in log package.
 func Panicln(v ...any) {
-	msg := fmt.Sprintln(v...)
-	evt := std.CreateEvent(LvFatal, msg)
-	std.Output(2, evt)
-	panic(msg)
+	std.panic(2, fmt.Sprintln(v...))
 }
 
 // Log logs a message using the default logger at the specified severity level.
 func Log(lv Level, msg string) {
-	evt := std.CreateEvent(lv, msg)
-	std.Output(2, evt)
+	std.log(2, lv, msg)
 }
 
 // Logf logs a formatted message using the default logger at the specified
 // severity level.
 func Logf(lv Level, msg string, a ...interface{}) {
-	evt := std.CreateEvent(lv, fmt.Sprintf(msg, a...))
-	std.Output(2, evt)
+	std.log(2, lv, fmt.Sprintf(msg, a...))
+}
+
+// Logln logs a message using the default logger at the specified severity
+// level. Arguments are handled in the manner of fmt.Println.
+func Logln(lv Level, v ...any) {
+	std.log(2, lv, fmt.Sprintln(v...))
 }
 
 // Trace logs a message with severity level TRACE using the default logger.
 func Trace(msg string) {
-	evt := std.CreateEvent(LvTrace, msg)
-	std.Output(2, evt)
+	std.log(2, LvTrace, msg)
 }
 
 // Tracef logs a formatted message with severity level TRACE using the default
 // logger.
 func Tracef(msg string, a ...interface{}) {
-	evt := std.CreateEvent(LvTrace, fmt.Sprintf(msg, a...))
-	std.Output(2, evt)
+	std.log(2, LvTrace, fmt.Sprintf(msg, a...))
+}
+
+// Traceln logs a message with severity level TRACE using the default logger.
+// Arguments are handled in the manner of fmt.Println.
+func Traceln(v ...any) {
+	std.log(2, LvTrace, fmt.Sprintln(v...))
 }
 
 // Debug logs a message with severity level DEBUG using the default logger.
 func Debug(msg string) {
-	evt := std.CreateEvent(LvDebug, msg)
-	std.Output(2, evt)
+	std.log(2, LvDebug, msg)
 }
 
 // Debugf logs a formatted message with severity level DEBUG using the default
 // logger.
 func Debugf(msg string, a ...interface{}) {
-	evt := std.CreateEvent(LvDebug, fmt.Sprintf(msg, a...))
-	std.Output(2, evt)
+	std.log(2, LvDebug, fmt.Sprintf(msg, a...))
+}
+
+// Debugln logs a message with severity level DEBUG using the default logger.
+// Arguments are handled in the manner of fmt.Println.
+func Debugln(v ...any) {
+	std.log(2, LvDebug, fmt.Sprintln(v...))
 }
 
 // Info logs a message with severity level INFO using the default logger.
 func Info(msg string) {
-	evt := std.CreateEvent(LvInfo, msg)
-	std.Output(2, evt)
+	std.log(2, LvInfo, msg)
 }
 
 // Infof logs a formatted message with severity level INFO using the default
 // logger.
 func Infof(msg string, a ...interface{}) {
-	evt := std.CreateEvent(LvInfo, fmt.Sprintf(msg, a...))
-	std.Output(2, evt)
+	std.log(2, LvInfo, fmt.Sprintf(msg, a...))
+}
+
+// Infoln logs a message with severity level INFO using the default logger.
+// Arguments are handled in the manner of fmt.Println.
+func Infoln(v ...any) {
+	std.log(2, LvInfo, fmt.Sprintln(v...))
 }
 
 // Warn logs a message with severity level WARN using the default logger.
 func Warn(msg string) {
-	evt := std.CreateEvent(LvWarn, msg)
-	std.Output(2, evt)
+	std.log(2, LvWarn, msg)
 }
 
 // Warnf logs a formatted message with severity level WARN using the default
 // logger.
 func Warnf(msg string, a ...interface{}) {
-	evt := std.CreateEvent(LvWarn, fmt.Sprintf(msg, a...))
-	std.Output(2, evt)
+	std.log(2, LvWarn, fmt.Sprintf(msg, a...))
+}
+
+// Warnln logs a message with severity level WARN using the default logger.
+// Arguments are handled in the manner of fmt.Println.
+func Warnln(v ...any) {
+	std.log(2, LvWarn, fmt.Sprintln(v...))
 }
 
 // Error logs a message with severity level ERROR using the default logger.
 func Error(msg string) {
-	evt := std.CreateEvent(LvError, msg)
-	std.Output(2, evt)
+	std.log(2, LvError, msg)
 }
 
 // Errorf logs a formatted message with severity level ERROR using the default
 // logger.
 func Errorf(msg string, a ...interface{}) {
-	evt := std.CreateEvent(LvError, fmt.Sprintf(msg, a...))
-	std.Output(2, evt)
+	std.log(2, LvError, fmt.Sprintf(msg, a...))
+}
+
+// Errorln logs a message with severity level ERROR using the default logger.
+// Arguments are handled in the manner of fmt.Println.
+func Errorln(v ...any) {
+	std.log(2, LvError, fmt.Sprintln(v...))
 }
@@ -0,0 +1,266 @@
+package jellog
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DefaultJournaldSocket is the path to the socket that systemd-journald listens
+// on for entries sent using its native protocol.
+const DefaultJournaldSocket = "/run/systemd/journal/socket"
+
+// JournaldOptions is used to control the behavior of a JournaldHandler. It is
+// passed to OpenJournald as an optional argument.
+type JournaldOptions struct {
+	// HandlerOptions is the generic Handler options. If Formatter is set, its
+	// output is used as the MESSAGE field of each entry, with any trailing
+	// newline removed. Otherwise, the event's message is used as-is.
+	HandlerOptions[string]
+
+	// Socket is the path of the journald socket to send entries to. If not
+	// set, DefaultJournaldSocket is used.
+	Socket string
+
+	// Identifier is the value of the SYSLOG_IDENTIFIER field of each entry. If
+	// not set, the base name of the running program is used.
+	Identifier string
+
+	// ComponentField is the name of the journal field that the component of an
+	// event is recorded in. If not set, "JELLOG_COMPONENT" is used. Events
+	// without a component do not include the field. It is converted to a valid
+	// journal field name as Attr keys are, and OpenJournald returns an error if
+	// nothing usable remains or if it names one of the fields that the
+	// JournaldHandler sets itself.
+	ComponentField string
+}
+
+// JournaldHandler is a Handler[string] that sends log events to
+// systemd-journald using its native datagram protocol. Each event is sent as a
+// single journal entry with the level mapped to the PRIORITY field, the source
+// code location recorded in the CODE_FILE, CODE_LINE, and CODE_FUNC fields, any
+// logged error and its causes recorded in the ERROR and ERROR_CAUSES fields,
+// any stack trace recorded in the STACK_TRACE field, and each of the event's
+// Attrs recorded in its own field. It should be created via a call to
+// OpenJournald and should not be used on its own.
+//
+// Attr keys are converted to journal field names by making them uppercase and
+// replacing anything but letters and digits with underscores. An Attr whose
+// field name would be one that the JournaldHandler sets itself, or one that
+// journald gives special meaning to such as PRIORITY or anything starting with
+// SYSLOG_ or CODE_, has "ATTR_" added to the front of it so that it cannot
+// replace or duplicate those fields.
+//
+// Entries too large to fit in a single datagram are written to a sealed memfd
+// (or an unlinked temporary file if memfd is not available) whose descriptor is
+// then passed to journald, as journald expects.
+//
+// A JournaldHandler is safe to use from multiple goroutines. Journald is only
+// available on Linux; on other platforms, OpenJournald always returns an error.
+type JournaldHandler struct {
+	opts           HandlerOptions[string]
+	addr           *net.UnixAddr
+	conn           *net.UnixConn
+	identifier     string
+	componentField string
+}
+
+// MustOpenJournald is the same as OpenJournald but panics if an error would
+// occur.
+func MustOpenJournald(opts *JournaldOptions) *JournaldHandler {
+	jh, err := OpenJournald(opts)
+	if err != nil {
+		panic(err)
+	}
+	return jh
+}
+
+// InsertBreak does nothing and returns nil. Journal entries are always
+// unambiguously separated from each other.
+func (jh *JournaldHandler) InsertBreak() error {
+	return nil
+}
+
+// HandlerOptions returns the options that the JournaldHandler is configured
+// with. Modifying the returned struct has no effect on jh.
+func (jh *JournaldHandler) HandlerOptions() HandlerOptions[string] {
+	return jh.opts
+}
+
+// Output sends a log event to journald as a single journal entry.
+//
+// The calldepth argument is used for recovering the program counter. It should
+// be supplied with the number of levels into the jellog package that the caller
+// has reached, with the externally called function counting as 1.
+func (jh *JournaldHandler) Output(calldepth int, evt Event[string]) error {
+	if jh.conn == nil {
+		return fmt.Errorf("Output() called on JournaldHandler created without OpenJournald")
+	}
+
+	// chain our component with the event's component if we have one
+	if jh.opts.Component != "" {
+		if evt.Component != "" {
+			evt.Component += "."
+		}
+		evt.Component += jh.opts.Component
+	}
+
+	if evt.PC == 0 {
+		evt.PC = callerPC(calldepth)
+	}
+
+	msg := evt.Message
+	if jh.opts.Formatter != nil {
+		msg = string(jh.opts.Formatter.Format(evt))
+	}
+	msg = strings.TrimSuffix(msg, "\n")
+
+	var buf []byte
+	buf = appendJournalField(buf, "MESSAGE", msg)
+	buf = appendJournalField(buf, "PRIORITY", strconv.Itoa(journalPriority(evt.Level)))
+	buf = appendJournalField(buf, "SYSLOG_IDENTIFIER", jh.identifier)
+	if evt.Component != "" {
+		buf = appendJournalField(buf, jh.componentField, evt.Component)
+	}
+	if file, line, fn, ok := evt.Caller(); ok {
+		buf = appendJournalField(buf, "CODE_FILE", file)
+		buf = appendJournalField(buf, "CODE_LINE", strconv.Itoa(line))
+		buf = appendJournalField(buf, "CODE_FUNC", fn)
+	}
+	if evt.Err != nil {
+		buf = appendJournalField(buf, "ERROR", evt.Err.Error())
+		if causes := appendCauses(nil, evt.Causes); len(causes) > 0 {
+			buf = appendJournalField(buf, "ERROR_CAUSES", string(causes))
+		}
+	}
+	if len(evt.Stack) > 0 {
+		buf = appendJournalField(buf, "STACK_TRACE", string(appendStack(nil, evt.Stack)))
+	}
+	for _, a := range evt.Attrs {
+		name := journalFieldName(a.Key)
+		if name == "" {
+			continue
+		}
+		if name == jh.componentField || journalFieldReserved(name) {
+			name = journalFieldName("ATTR_" + name)
+		}
+		buf = appendJournalField(buf, name, fmt.Sprint(resolveLogValue(a.Value)))
+	}
+
+	return jh.send(buf)
+}
+
+func defaultJournaldOptions(opts *JournaldOptions) (JournaldOptions, error) {
+	var o JournaldOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.Socket == "" {
+		o.Socket = DefaultJournaldSocket
+	}
+	if o.Identifier == "" {
+		o.Identifier = filepath.Base(os.Args[0])
+	}
+	if o.ComponentField == "" {
+		o.ComponentField = "JELLOG_COMPONENT"
+	} else {
+		field := journalFieldName(o.ComponentField)
+		if field == "" {
+			return o, fmt.Errorf("component field %q has no characters usable in a journal field name", o.ComponentField)
+		}
+		if journalFieldReserved(field) {
+			return o, fmt.Errorf("component field %q is reserved for use by the handler or by journald", o.ComponentField)
+		}
+		o.ComponentField = field
+	}
+	return o, nil
+}
+
+// journalFieldReserved returns whether name is a journal field that a
+// JournaldHandler sets itself or that journald treats specially, and which
+// therefore must not be set from an Attr.
+func journalFieldReserved(name string) bool {
+	switch name {
+	case "MESSAGE", "MESSAGE_ID", "PRIORITY", "ERRNO", "STACK_TRACE":
+		return true
+	}
+	return strings.HasPrefix(name, "SYSLOG_") ||
+		strings.HasPrefix(name, "CODE_") ||
+		strings.HasPrefix(name, "ERROR")
+}
+
+// journalPriority maps a Level to the syslog-style priority used by journald.
+// Custom levels are mapped to the priority of the closest built-in level at or
+// below them, with levels between LvInfo and LvWarn mapped to "notice".
+func journalPriority(lv Level) int {
+	switch {
+	case lv.Severity >= LvFatal.Severity:
+		return 2 // crit
+	case lv.Severity >= LvError.Severity:
+		return 3 // err
+	case lv.Severity >= LvWarn.Severity:
+		return 4 // warning
+	case lv.Severity > LvInfo.Severity:
+		return 5 // notice
+	case lv.Severity == LvInfo.Severity:
+		return 6 // info
+	default:
+		return 7 // debug
+	}
+}
+
+// appendJournalField appends a single field in journald's native format to
+// buf. Values that contain a newline must be length-prefixed instead of being
+// written as KEY=VALUE.
+func appendJournalField(buf []byte, name, value string) []byte {
+	buf = append(buf, name...)
+	if strings.ContainsRune(value, '\n') {
+		buf = append(buf, '\n')
+		buf = binary.LittleEndian.AppendUint64(buf, uint64(len(value)))
+	} else {
+		buf = append(buf, '=')
+	}
+	buf = append(buf, value...)
+	buf = append(buf, '\n')
+	return buf
+}
+
+// journalFieldName converts name into a valid journal field name, which may
+// only contain uppercase letters, digits, and underscores, must not start with
+// an underscore or digit, and may be at most 64 characters long. An empty
+// string is returned if nothing usable remains.
+func journalFieldName(name string) string {
+	var sb strings.Builder
+	for _, ch := range strings.ToUpper(name) {
+		if (ch >= 'A' && ch <= 'Z') || (ch >= '0' && ch <= '9') {
+			sb.WriteRune(ch)
+		} else if sb.Len() > 0 {
+			// leading underscores are reserved for trusted fields, so only
+			// allow them after something else
+			sb.WriteByte('_')
+		}
+	}
+
+	field := sb.String()
+	if field != "" && field[0] >= '0' && field[0] <= '9' {
+		field = "F_" + field
+	}
+	if len(field) > 64 {
+		field = field[:64]
+	}
+	return field
+}
+
+// Close closes the connection to journald. Any further calls to Output return
+// an error.
+func (jh *JournaldHandler) Close(ctx context.Context) error {
+	if jh.conn == nil {
+		return nil
+	}
+	return jh.conn.Close()
+}
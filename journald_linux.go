@@ -0,0 +1,142 @@
+//go:build linux
+
+package jellog
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"runtime"
+	"syscall"
+	"unsafe"
+)
+
+// flags and seals for memfd_create(2) and fcntl(2) that are not included in the
+// frozen syscall package.
+const (
+	mfdCloexec       = 0x1
+	mfdAllowSealing  = 0x2
+	fcntlAddSeals    = 1033
+	sealsForJournald = 0x1 | 0x2 | 0x4 | 0x8 // SEAL, SHRINK, GROW, WRITE
+)
+
+// OpenJournald gets a journald-based Handler ready for logging. The journald
+// socket must already exist; an error is returned if it does not.
+//
+// To use the default set of JournaldOptions, pass nil for opts.
+func OpenJournald(opts *JournaldOptions) (*JournaldHandler, error) {
+	o, err := defaultJournaldOptions(opts)
+	if err != nil {
+		return &JournaldHandler{}, err
+	}
+
+	if _, err := os.Stat(o.Socket); err != nil {
+		return &JournaldHandler{}, fmt.Errorf("cannot find journald socket: %w", err)
+	}
+
+	// use an unconnected, autobound socket so that a restart of journald does
+	// not leave us with a dead connection.
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Net: "unixgram"})
+	if err != nil {
+		return &JournaldHandler{}, fmt.Errorf("cannot create socket: %w", err)
+	}
+
+	jh := &JournaldHandler{
+		opts:           o.HandlerOptions,
+		addr:           &net.UnixAddr{Name: o.Socket, Net: "unixgram"},
+		conn:           conn,
+		identifier:     o.Identifier,
+		componentField: o.ComponentField,
+	}
+
+	return jh, nil
+}
+
+// send writes a fully-encoded entry to journald. If the entry is too large for
+// a single datagram, it is written to a sealed memfd and the descriptor is sent
+// instead.
+func (jh *JournaldHandler) send(entry []byte) error {
+	_, _, err := jh.conn.WriteMsgUnix(entry, nil, jh.addr)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, syscall.EMSGSIZE) && !errors.Is(err, syscall.ENOBUFS) {
+		return err
+	}
+
+	f, err := journaldEntryFile(entry)
+	if err != nil {
+		return fmt.Errorf("entry too large for datagram and cannot create entry file: %w", err)
+	}
+	defer f.Close()
+
+	_, _, err = jh.conn.WriteMsgUnix(nil, syscall.UnixRights(int(f.Fd())), jh.addr)
+	return err
+}
+
+// journaldEntryFile returns a file containing entry that journald will accept
+// the descriptor of. A sealed memfd is used if the kernel supports it;
+// otherwise an unlinked temporary file in /dev/shm is used.
+func journaldEntryFile(entry []byte) (*os.File, error) {
+	f, err := memfdCreate("journal-entry")
+	if err == nil {
+		if _, err := f.Write(entry); err != nil {
+			f.Close()
+			return nil, err
+		}
+		_, _, errno := syscall.Syscall(syscall.SYS_FCNTL, f.Fd(), fcntlAddSeals, sealsForJournald)
+		if errno != 0 {
+			f.Close()
+			return nil, fmt.Errorf("seal memfd: %w", errno)
+		}
+		return f, nil
+	}
+
+	f, err = os.CreateTemp("/dev/shm", "journal-entry.*")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Remove(f.Name()); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if _, err := f.Write(entry); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// memfdCreate calls memfd_create(2). The syscall package does not include its
+// number on every architecture, so it is looked up here.
+func memfdCreate(name string) (*os.File, error) {
+	var trap uintptr
+	switch runtime.GOARCH {
+	case "amd64":
+		trap = 319
+	case "386":
+		trap = 356
+	case "arm":
+		trap = 385
+	case "arm64", "riscv64", "loong64":
+		trap = 279
+	case "ppc64", "ppc64le":
+		trap = 360
+	case "s390x":
+		trap = 350
+	default:
+		return nil, syscall.ENOSYS
+	}
+
+	namePtr, err := syscall.BytePtrFromString(name)
+	if err != nil {
+		return nil, err
+	}
+
+	fd, _, errno := syscall.Syscall(trap, uintptr(unsafe.Pointer(namePtr)), mfdCloexec|mfdAllowSealing, 0)
+	if errno != 0 {
+		return nil, errno
+	}
+	return os.NewFile(fd, name), nil
+}
@@ -0,0 +1,299 @@
+//go:build linux
+
+package jellog_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/dekarrin/jellog"
+)
+
+// journalSocket is a stand-in for journald that receives entries sent by a
+// JournaldHandler.
+type journalSocket struct {
+	t    *testing.T
+	path string
+	conn *net.UnixConn
+}
+
+func listenJournal(t *testing.T) *journalSocket {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "journal.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("cannot listen on unixgram socket: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return &journalSocket{t: t, path: path, conn: conn}
+}
+
+// open opens a JournaldHandler that sends to js, with opts applied on top of
+// the socket path.
+func (js *journalSocket) open(opts jellog.JournaldOptions) *jellog.JournaldHandler {
+	js.t.Helper()
+
+	opts.Socket = js.path
+	jh, err := jellog.OpenJournald(&opts)
+	if err != nil {
+		js.t.Fatalf("OpenJournald: %v", err)
+	}
+	js.t.Cleanup(func() { jh.Close(context.Background()) })
+	return jh
+}
+
+// receive reads the next entry, either directly from a datagram or from a
+// file descriptor passed in one, and returns it unparsed along with whether it
+// came from a descriptor.
+func (js *journalSocket) receive() ([]byte, bool) {
+	js.t.Helper()
+
+	if err := js.conn.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		js.t.Fatalf("set deadline: %v", err)
+	}
+
+	buf := make([]byte, 256*1024)
+	oob := make([]byte, syscall.CmsgSpace(4))
+	n, oobn, _, _, err := js.conn.ReadMsgUnix(buf, oob)
+	if err != nil {
+		js.t.Fatalf("read entry: %v", err)
+	}
+	if oobn == 0 {
+		return buf[:n], false
+	}
+
+	msgs, err := syscall.ParseSocketControlMessage(oob[:oobn])
+	if err != nil || len(msgs) != 1 {
+		js.t.Fatalf("parse control message: %v", err)
+	}
+	fds, err := syscall.ParseUnixRights(&msgs[0])
+	if err != nil || len(fds) != 1 {
+		js.t.Fatalf("parse unix rights: %v", err)
+	}
+	f := os.NewFile(uintptr(fds[0]), "entry")
+	defer f.Close()
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		js.t.Fatalf("seek entry file: %v", err)
+	}
+	entry, err := io.ReadAll(f)
+	if err != nil {
+		js.t.Fatalf("read entry file: %v", err)
+	}
+	return entry, true
+}
+
+// parseJournalEntry parses an entry in journald's native format into the
+// values of each field, in the order they appear.
+func parseJournalEntry(t *testing.T, entry []byte) map[string][]string {
+	t.Helper()
+
+	fields := map[string][]string{}
+	for len(entry) > 0 {
+		nl := bytes.IndexByte(entry, '\n')
+		if nl < 0 {
+			t.Fatalf("entry has unterminated field: %q", entry)
+		}
+		line := entry[:nl]
+		entry = entry[nl+1:]
+
+		if eq := bytes.IndexByte(line, '='); eq >= 0 {
+			fields[string(line[:eq])] = append(fields[string(line[:eq])], string(line[eq+1:]))
+			continue
+		}
+
+		// binary-safe field; the length is given first
+		if len(entry) < 8 {
+			t.Fatalf("field %q is missing its length", line)
+		}
+		size := binary.LittleEndian.Uint64(entry)
+		entry = entry[8:]
+		if uint64(len(entry)) < size+1 || entry[size] != '\n' {
+			t.Fatalf("field %q has bad length %d", line, size)
+		}
+		fields[string(line)] = append(fields[string(line)], string(entry[:size]))
+		entry = entry[size+1:]
+	}
+	return fields
+}
+
+func Test_JournaldHandler_Output(t *testing.T) {
+	testCases := []struct {
+		name   string
+		opts   jellog.JournaldOptions
+		evt    jellog.Event[string]
+		expect map[string]string
+		absent []string
+	}{
+		{
+			name: "basic fields",
+			opts: jellog.JournaldOptions{Identifier: "testprog"},
+			evt:  jellog.Event[string]{Level: jellog.LvWarn, Message: "disk is getting full\n"},
+			expect: map[string]string{
+				"MESSAGE":           "disk is getting full",
+				"PRIORITY":          "4",
+				"SYSLOG_IDENTIFIER": "testprog",
+			},
+			absent: []string{"JELLOG_COMPONENT", "ERROR", "STACK_TRACE"},
+		},
+		{
+			name: "component is chained and uses the configured field",
+			opts: jellog.JournaldOptions{
+				HandlerOptions: jellog.HandlerOptions[string]{Component: "server"},
+				ComponentField: "my-component",
+			},
+			evt: jellog.Event[string]{Level: jellog.LvError, Component: "db", Message: "lost connection"},
+			expect: map[string]string{
+				"PRIORITY":     "3",
+				"MY_COMPONENT": "db.server",
+			},
+		},
+		{
+			name: "multi-line message is sent length-prefixed",
+			evt:  jellog.Event[string]{Level: jellog.LvInfo, Message: "line one\nFORGED=yes\nline three"},
+			expect: map[string]string{
+				"MESSAGE": "line one\nFORGED=yes\nline three",
+			},
+			absent: []string{"FORGED"},
+		},
+		{
+			name: "attrs become fields",
+			evt: jellog.Event[string]{Level: jellog.LvInfo, Message: "request done", Attrs: []jellog.Attr{
+				{Key: "user.id", Value: 42},
+				{Key: "9lives", Value: "cat"},
+			}},
+			expect: map[string]string{
+				"USER_ID":  "42",
+				"F_9LIVES": "cat",
+			},
+		},
+		{
+			name: "attrs cannot set reserved fields",
+			evt: jellog.Event[string]{Level: jellog.LvInfo, Component: "real", Message: "real message", Attrs: []jellog.Attr{
+				{Key: "priority", Value: "0"},
+				{Key: "message", Value: "fake message"},
+				{Key: "syslog_identifier", Value: "fake"},
+				{Key: "code_file", Value: "fake.go"},
+				{Key: "error", Value: "fake"},
+				{Key: "stack_trace", Value: "fake"},
+				{Key: "jellog_component", Value: "fake"},
+			}},
+			expect: map[string]string{
+				"MESSAGE":                "real message",
+				"PRIORITY":               "6",
+				"JELLOG_COMPONENT":       "real",
+				"ATTR_PRIORITY":          "0",
+				"ATTR_MESSAGE":           "fake message",
+				"ATTR_SYSLOG_IDENTIFIER": "fake",
+				"ATTR_CODE_FILE":         "fake.go",
+				"ATTR_ERROR":             "fake",
+				"ATTR_STACK_TRACE":       "fake",
+				"ATTR_JELLOG_COMPONENT":  "fake",
+			},
+			absent: []string{"ERROR", "STACK_TRACE"},
+		},
+		{
+			name: "error causes are escaped",
+			evt: jellog.Event[string]{Level: jellog.LvError, Message: "failed", Err: errors.New("outer"), Causes: []jellog.Cause{
+				{Message: "outer", Type: "*errors.errorString"},
+				{Message: "inner\x1b[31m\rforged", Type: "*errors.errorString", Depth: 1},
+			}},
+			expect: map[string]string{
+				"ERROR":        "outer",
+				"ERROR_CAUSES": "\tcaused by: inner\\x1b[31m\\rforged [*errors.errorString]\n",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			js := listenJournal(t)
+			jh := js.open(tc.opts)
+
+			if err := jh.Output(1, tc.evt); err != nil {
+				t.Fatalf("Output: %v", err)
+			}
+
+			entry, _ := js.receive()
+			fields := parseJournalEntry(t, entry)
+
+			for name, vals := range fields {
+				if len(vals) > 1 {
+					t.Errorf("field %s appears %d times: %q", name, len(vals), vals)
+				}
+			}
+			for name, expect := range tc.expect {
+				if vals := fields[name]; len(vals) < 1 || vals[0] != expect {
+					t.Errorf("field %s: expected %q, got %q", name, expect, vals)
+				}
+			}
+			for _, name := range tc.absent {
+				if vals, ok := fields[name]; ok {
+					t.Errorf("field %s: expected to be absent, got %q", name, vals)
+				}
+			}
+		})
+	}
+}
+
+func Test_JournaldHandler_Output_largeEntry(t *testing.T) {
+	js := listenJournal(t)
+	jh := js.open(jellog.JournaldOptions{})
+
+	// far bigger than the default maximum datagram size, so it must be passed
+	// to journald through a file descriptor
+	msg := strings.Repeat("0123456789abcdef", 64*1024)
+	if err := jh.Output(1, jellog.Event[string]{Level: jellog.LvInfo, Message: msg}); err != nil {
+		t.Fatalf("Output: %v", err)
+	}
+
+	entry, viaFD := js.receive()
+	if !viaFD {
+		t.Fatalf("expected entry of %d bytes to be sent via file descriptor", len(entry))
+	}
+	fields := parseJournalEntry(t, entry)
+	if vals := fields["MESSAGE"]; len(vals) != 1 || vals[0] != msg {
+		t.Errorf("MESSAGE was not received intact")
+	}
+}
+
+func Test_OpenJournald_badComponentField(t *testing.T) {
+	testCases := []struct {
+		name  string
+		field string
+	}{
+		{name: "no usable characters", field: "--"},
+		{name: "reserved field", field: "priority"},
+		{name: "reserved prefix", field: "syslog_pid"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			js := listenJournal(t)
+
+			_, err := jellog.OpenJournald(&jellog.JournaldOptions{Socket: js.path, ComponentField: tc.field})
+			if err == nil {
+				t.Errorf("expected error for component field %q", tc.field)
+			}
+		})
+	}
+}
+
+func Test_OpenJournald_missingSocket(t *testing.T) {
+	_, err := jellog.OpenJournald(&jellog.JournaldOptions{Socket: filepath.Join(t.TempDir(), "nope.sock")})
+	if err == nil {
+		t.Errorf("expected error for missing socket")
+	}
+}
@@ -0,0 +1,19 @@
+//go:build !linux
+
+package jellog
+
+import (
+	"errors"
+)
+
+var errJournaldUnsupported = errors.New("journald is only supported on linux")
+
+// OpenJournald gets a journald-based Handler ready for logging. Journald is
+// only available on Linux, so on this platform an error is always returned.
+func OpenJournald(opts *JournaldOptions) (*JournaldHandler, error) {
+	return &JournaldHandler{}, errJournaldUnsupported
+}
+
+func (jh *JournaldHandler) send(entry []byte) error {
+	return errJournaldUnsupported
+}
@@ -1,7 +1,11 @@
 package jellog
 
 import (
+	"fmt"
 	"math"
+	"strconv"
+	"strings"
+	"sync"
 )
 
 // Level is a level of severity of a log event. It has both the severity itself
@@ -9,6 +13,17 @@ import (
 //
 // When a Handler is added to a Logger, a Level is also provided which indicates
 // the minimum severity of events that should be routed to it.
+//
+// Custom levels can be created simply by declaring a new Level, but they should
+// also be added to the level registry with RegisterLevel so that they can be
+// found by name with LevelByName and ParseLevel and so that their names are
+// preserved in places where jellog only has the severity of a level to go on.
+//
+// *Level implements encoding.TextMarshaler and encoding.TextUnmarshaler, so a
+// Level can be used directly in configuration files, and flag.Value, so it can
+// be used directly as a command-line flag. As with any pointer method, the
+// encoding packages only use them for a Level that is addressable, so a
+// configuration struct holding a Level should be marshaled through a pointer.
 type Level struct {
 	Name     string
 	Severity int
@@ -74,6 +89,191 @@ var (
 	LvAll = Level{"ALL", math.MaxInt}
 )
 
+var (
+	levelRegMtx    sync.RWMutex
+	levelsByName   = map[string]Level{}
+	levelsBySevReg = map[int]Level{}
+)
+
+func init() {
+	builtins := []struct {
+		lv      Level
+		aliases []string
+	}{
+		{LvTrace, nil},
+		{LvDebug, nil},
+		{LvInfo, []string{"information"}},
+		{LvWarn, []string{"warning"}},
+		{LvError, []string{"err"}},
+		{LvFatal, []string{"crit", "critical"}},
+		{LvAll, nil},
+	}
+	for _, b := range builtins {
+		if err := RegisterLevel(b.lv, b.aliases...); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// RegisterLevel adds lv to the registry of known levels along with any aliases
+// it should also be found by. Lookups of names and aliases are not case
+// sensitive. All of the built-in levels are registered automatically, along
+// with the aliases "information" for LvInfo, "warning" for LvWarn, "err" for
+// LvError, and "crit" and "critical" for LvFatal.
+//
+// An error is returned if lv has no name, if its name or one of the aliases is
+// already registered to a level with a different severity, or if a level with
+// the same severity but a different name is already registered. Registering the
+// same level more than once is allowed, and can be used to add more aliases.
+func RegisterLevel(lv Level, aliases ...string) error {
+	if lv.Name == "" {
+		return fmt.Errorf("level with severity %d has no name", lv.Severity)
+	}
+
+	levelRegMtx.Lock()
+	defer levelRegMtx.Unlock()
+
+	if existing, ok := levelsBySevReg[lv.Severity]; ok && !strings.EqualFold(existing.Name, lv.Name) {
+		return fmt.Errorf("severity %d is already registered to level %q", lv.Severity, existing.Name)
+	}
+
+	names := append([]string{lv.Name}, aliases...)
+	for _, n := range names {
+		if existing, ok := levelsByName[strings.ToLower(n)]; ok && existing.Severity != lv.Severity {
+			return fmt.Errorf("name %q is already registered to level %q", n, existing.Name)
+		}
+	}
+
+	levelsBySevReg[lv.Severity] = lv
+	for _, n := range names {
+		levelsByName[strings.ToLower(n)] = lv
+	}
+	return nil
+}
+
+// LevelByName returns the registered Level with the given name or alias. Case
+// is ignored. If no level with that name has been registered, ok will be false.
+func LevelByName(name string) (lv Level, ok bool) {
+	levelRegMtx.RLock()
+	defer levelRegMtx.RUnlock()
+
+	lv, ok = levelsByName[strings.ToLower(name)]
+	return lv, ok
+}
+
+// LevelBySeverity returns the registered Level with the given severity. If no
+// level with that severity has been registered, ok will be false.
+func LevelBySeverity(sev int) (lv Level, ok bool) {
+	levelRegMtx.RLock()
+	defer levelRegMtx.RUnlock()
+
+	lv, ok = levelsBySevReg[sev]
+	return lv, ok
+}
+
+// ParseLevel returns the Level that s refers to. s may be the name or an alias
+// of a registered level, in any case, or it may be an integer severity. If a
+// severity is given that is not registered, a Level with that severity and no
+// name is returned.
+func ParseLevel(s string) (Level, error) {
+	s = strings.TrimSpace(s)
+	if lv, ok := LevelByName(s); ok {
+		return lv, nil
+	}
+
+	sev, err := strconv.Atoi(s)
+	if err != nil {
+		return Level{}, fmt.Errorf("unknown level %q", s)
+	}
+	if lv, ok := LevelBySeverity(sev); ok {
+		return lv, nil
+	}
+	return Level{Severity: sev}, nil
+}
+
+// ParseComponentLevels parses a list of per-component minimum levels suitable
+// for use as Options.ComponentLevels. The list is made of comma-separated
+// entries of the form COMPONENT=LEVEL, such as "db.pool=TRACE,http=WARN", with
+// each level given in any form accepted by ParseLevel. An entry that is just a
+// level, with no component, sets the level for the key "", which applies to all
+// components not otherwise listed. Whitespace around entries is ignored, as are
+// empty entries.
+func ParseComponentLevels(s string) (map[string]Level, error) {
+	levels := make(map[string]Level)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		comp, lvName, ok := strings.Cut(entry, "=")
+		if !ok {
+			comp, lvName = "", entry
+		}
+		comp = strings.TrimSpace(comp)
+
+		lv, err := ParseLevel(lvName)
+		if err != nil {
+			if comp == "" {
+				return nil, err
+			}
+			return nil, fmt.Errorf("component %q: %w", comp, err)
+		}
+		levels[comp] = lv
+	}
+	return levels, nil
+}
+
+// String returns the name of lv. If lv has no name, the name it is registered
+// under is used, and if it isn't registered, its severity is returned as a
+// number.
+func (lv Level) String() string {
+	return lv.resolved().displayName()
+}
+
+// MarshalText converts lv to its text representation, which is the same as is
+// returned by String. It never returns a non-nil error.
+func (lv *Level) MarshalText() ([]byte, error) {
+	return []byte(lv.String()), nil
+}
+
+// UnmarshalText sets lv to the Level that text refers to. text is interpreted
+// the same way as by ParseLevel.
+func (lv *Level) UnmarshalText(text []byte) error {
+	parsed, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*lv = parsed
+	return nil
+}
+
+// Set sets lv to the Level that s refers to. s is interpreted the same way as
+// by ParseLevel. Along with String, this allows a *Level to be used as a
+// flag.Value.
+func (lv *Level) Set(s string) error {
+	return lv.UnmarshalText([]byte(s))
+}
+
+// resolved returns lv with its name filled in from the registry if it doesn't
+// already have one.
+func (lv Level) resolved() Level {
+	if lv.Name != "" {
+		return lv
+	}
+	if reg, ok := LevelBySeverity(lv.Severity); ok {
+		return reg
+	}
+	return lv
+}
+
+func (lv Level) displayName() string {
+	if lv.Name != "" {
+		return lv.Name
+	}
+	return strconv.Itoa(lv.Severity)
+}
+
 func minPossibleSeverity() int {
 	return math.MinInt
 }
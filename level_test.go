@@ -0,0 +1,245 @@
+package jellog_test
+
+import (
+	"encoding"
+	"encoding/json"
+	"flag"
+	"testing"
+
+	"github.com/dekarrin/jellog"
+)
+
+func Test_ParseComponentLevels(t *testing.T) {
+	testCases := []struct {
+		name      string
+		input     string
+		expect    map[string]jellog.Level
+		expectErr bool
+	}{
+		{
+			name:   "components",
+			input:  "db.pool=TRACE,http=warn",
+			expect: map[string]jellog.Level{"db.pool": jellog.LvTrace, "http": jellog.LvWarn},
+		},
+		{
+			name:   "level alone sets root",
+			input:  "ERROR, db=DEBUG",
+			expect: map[string]jellog.Level{"": jellog.LvError, "db": jellog.LvDebug},
+		},
+		{
+			name:   "whitespace and empty entries",
+			input:  " db = INFO ,, ",
+			expect: map[string]jellog.Level{"db": jellog.LvInfo},
+		},
+		{
+			name:   "empty",
+			input:  "",
+			expect: map[string]jellog.Level{},
+		},
+		{
+			name:      "bad level",
+			input:     "db=LOUD",
+			expectErr: true,
+		},
+		{
+			name:      "bad root level",
+			input:     "LOUD",
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := jellog.ParseComponentLevels(tc.input)
+
+			if tc.expectErr {
+				if err == nil {
+					t.Errorf("expected error, got %v", actual)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(actual) != len(tc.expect) {
+				t.Fatalf("expected %v, got %v", tc.expect, actual)
+			}
+			for c, lv := range tc.expect {
+				if actual[c].Severity != lv.Severity {
+					t.Errorf("component %q: expected %s, got %s", c, lv, actual[c])
+				}
+			}
+		})
+	}
+}
+
+func Test_RegisterLevel(t *testing.T) {
+	notice := jellog.Level{Name: "TEST_NOTICE", Severity: 3101}
+
+	if err := jellog.RegisterLevel(notice, "test_note"); err != nil {
+		t.Fatalf("RegisterLevel: %v", err)
+	}
+
+	for _, name := range []string{"TEST_NOTICE", "test_notice", "Test_Note"} {
+		if lv, ok := jellog.LevelByName(name); !ok || lv != notice {
+			t.Errorf("LevelByName(%q): expected %v, got %v (%v)", name, notice, lv, ok)
+		}
+	}
+	if lv, ok := jellog.LevelBySeverity(notice.Severity); !ok || lv != notice {
+		t.Errorf("LevelBySeverity: expected %v, got %v (%v)", notice, lv, ok)
+	}
+
+	// the same level can be registered again to add aliases
+	if err := jellog.RegisterLevel(notice, "test_notify"); err != nil {
+		t.Errorf("expected registering the same level again to succeed, got %v", err)
+	}
+	if lv, ok := jellog.LevelByName("test_notify"); !ok || lv != notice {
+		t.Errorf("expected added alias to find %v, got %v (%v)", notice, lv, ok)
+	}
+
+	// a level with only a severity gets its registered name
+	if actual := (jellog.Level{Severity: notice.Severity}).String(); actual != "TEST_NOTICE" {
+		t.Errorf("expected unnamed level to be shown as TEST_NOTICE, got %q", actual)
+	}
+}
+
+func Test_RegisterLevel_errors(t *testing.T) {
+	testCases := []struct {
+		name    string
+		lv      jellog.Level
+		aliases []string
+	}{
+		{name: "no name", lv: jellog.Level{Severity: 3111}},
+		{name: "severity taken by another name", lv: jellog.Level{Name: "TEST_LOUD", Severity: jellog.LvWarn.Severity}},
+		{name: "name taken by another severity", lv: jellog.Level{Name: "warn", Severity: 3112}},
+		{name: "alias taken by another severity", lv: jellog.Level{Name: "TEST_QUIET", Severity: 3113}, aliases: []string{"quiet", "WARNING"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := jellog.RegisterLevel(tc.lv, tc.aliases...)
+
+			if err == nil {
+				t.Fatalf("expected error")
+			}
+			// nothing is registered when there is an error
+			if lv, ok := jellog.LevelBySeverity(tc.lv.Severity); ok && lv.Name != jellog.LvWarn.Name {
+				t.Errorf("expected severity %d not to be registered, got %v", tc.lv.Severity, lv)
+			}
+			for _, a := range tc.aliases {
+				if lv, ok := jellog.LevelByName(a); ok && lv.Severity == tc.lv.Severity {
+					t.Errorf("expected alias %q not to be registered", a)
+				}
+			}
+		})
+	}
+}
+
+func Test_ParseLevel(t *testing.T) {
+	testCases := []struct {
+		name      string
+		input     string
+		expect    jellog.Level
+		expectErr bool
+	}{
+		{name: "name", input: "WARN", expect: jellog.LvWarn},
+		{name: "any case", input: "wArN", expect: jellog.LvWarn},
+		{name: "alias", input: "critical", expect: jellog.LvFatal},
+		{name: "whitespace", input: "  info\n", expect: jellog.LvInfo},
+		{name: "registered severity", input: "-100", expect: jellog.LvDebug},
+		{name: "unregistered severity", input: "42", expect: jellog.Level{Severity: 42}},
+		{name: "unknown name", input: "LOUD", expectErr: true},
+		{name: "empty", input: "", expectErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := jellog.ParseLevel(tc.input)
+
+			if tc.expectErr {
+				if err == nil {
+					t.Errorf("expected error, got %v", actual)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if actual != tc.expect {
+				t.Errorf("expected %#v, got %#v", tc.expect, actual)
+			}
+		})
+	}
+}
+
+func Test_ParseLevel_roundTrip(t *testing.T) {
+	levels := []jellog.Level{
+		jellog.LvTrace, jellog.LvDebug, jellog.LvInfo, jellog.LvWarn,
+		jellog.LvError, jellog.LvFatal, jellog.LvAll,
+		{Severity: 77},
+		{Severity: -3},
+	}
+
+	for _, lv := range levels {
+		t.Run(lv.String(), func(t *testing.T) {
+			actual, err := jellog.ParseLevel(lv.String())
+			if err != nil {
+				t.Fatalf("ParseLevel(%q): %v", lv.String(), err)
+			}
+			if actual != lv {
+				t.Errorf("expected %#v, got %#v", lv, actual)
+			}
+
+			text, err := lv.MarshalText()
+			if err != nil {
+				t.Fatalf("MarshalText: %v", err)
+			}
+			var unmarshaled jellog.Level
+			if err := unmarshaled.UnmarshalText(text); err != nil {
+				t.Fatalf("UnmarshalText(%q): %v", text, err)
+			}
+			if unmarshaled != lv {
+				t.Errorf("expected %#v after text round trip, got %#v", lv, unmarshaled)
+			}
+		})
+	}
+}
+
+func Test_Level_text(t *testing.T) {
+	var _ encoding.TextMarshaler = &jellog.Level{}
+	var _ encoding.TextUnmarshaler = &jellog.Level{}
+	var _ flag.Value = &jellog.Level{}
+
+	type config struct {
+		Level jellog.Level `json:"level"`
+	}
+
+	data, err := json.Marshal(&config{Level: jellog.LvWarn})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if string(data) != `{"level":"WARN"}` {
+		t.Errorf("expected level to be marshaled as text, got %s", data)
+	}
+
+	var cfg config
+	if err := json.Unmarshal([]byte(`{"level":"error"}`), &cfg); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if cfg.Level != jellog.LvError {
+		t.Errorf("expected %v, got %v", jellog.LvError, cfg.Level)
+	}
+	if err := json.Unmarshal([]byte(`{"level":"loud"}`), &cfg); err == nil {
+		t.Errorf("expected error for unknown level")
+	}
+
+	lv := jellog.LvInfo
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(&lv, "level", "log level")
+	if err := fs.Parse([]string{"-level", "debug"}); err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if lv != jellog.LvDebug {
+		t.Errorf("expected flag to set %v, got %v", jellog.LvDebug, lv)
+	}
+}
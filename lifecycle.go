@@ -0,0 +1,262 @@
+package jellog
+
+import (
+	"context"
+	"reflect"
+)
+
+// Flusher is implemented by Handlers that buffer events or write them
+// asynchronously. Flush returns once every event the Handler has been given so
+// far has been written to its destination, or once ctx is done, whichever
+// comes first.
+//
+// A Handler that also implements HandlerWrapper should only flush its own
+// state in Flush, and not the Handlers it wraps; FlushHandlers and
+// Logger.Flush reach those separately.
+type Flusher interface {
+	Flush(ctx context.Context) error
+}
+
+// Closer is implemented by Handlers that hold resources, such as open files or
+// network connections, that should be released when logging is finished.
+// Close flushes any pending events and releases the resources; the Handler
+// must not be used afterwards. It should give up and return once ctx is done.
+//
+// A Handler that also implements HandlerWrapper should only close its own
+// state in Close, and not the Handlers it wraps; CloseHandlers and
+// Logger.Close reach those separately.
+type Closer interface {
+	Close(ctx context.Context) error
+}
+
+// HandlerWrapper is implemented by Handlers that pass events on to other
+// Handlers, such as DedupHandler and RouterHandler. It allows FlushHandlers and
+// CloseHandlers to reach every Handler exactly once, even when the same one is
+// wrapped by several others.
+type HandlerWrapper[E any] interface {
+	// WrappedHandlers returns the Handlers that events may be passed on to.
+	WrappedHandlers() []Handler[E]
+}
+
+// FlushHandlers flushes each of hs, and every Handler they wrap, that is a
+// Flusher. Each Handler is flushed once, no matter how many times it is
+// reached, and a wrapping Handler is always flushed before the Handlers it
+// wraps so that anything it emits while flushing is flushed as well. The
+// combined error of all Handlers is returned.
+func FlushHandlers[E any](ctx context.Context, hs ...Handler[E]) error {
+	return walkHandlers(ctx, hs, false)
+}
+
+// CloseHandlers closes each of hs, and every Handler they wrap, that is a
+// Closer, and flushes each that is a Flusher but not a Closer. Each Handler is
+// reached once, no matter how many times it is wrapped, and a wrapping Handler
+// is always closed before the Handlers it wraps. The combined error of all
+// Handlers is returned.
+func CloseHandlers[E any](ctx context.Context, hs ...Handler[E]) error {
+	return walkHandlers(ctx, hs, true)
+}
+
+// Flush flushes every Handler in the Logger as described in FlushHandlers. This
+// includes the Handlers of any Logger that was added to it with AsHandler.
+func (lg Logger[E]) Flush(ctx context.Context) error {
+	err := lg.drainWorkers(ctx, false)
+	return joinHandlerErr(err, FlushHandlers(ctx, lg.allHandlers()...))
+}
+
+// Close closes every Handler in the Logger as described in CloseHandlers. This
+// includes the Handlers of any Logger that was added to it with AsHandler. The
+// Logger should not be used after it is closed.
+func (lg Logger[E]) Close(ctx context.Context) error {
+	err := lg.drainWorkers(ctx, true)
+	return joinHandlerErr(err, CloseHandlers(ctx, lg.allHandlers()...))
+}
+
+// AsHandler returns a Handler that logs events with lg. This allows a Logger to
+// be added to another Logger, or to a Handler that wraps others, so that the
+// events sent to it also go to all of lg's Handlers. Breaks inserted in the
+// returned Handler are inserted in all of lg's Handlers.
+//
+// Flushing or closing a Logger that contains the returned Handler also flushes
+// or closes lg's Handlers.
+func (lg Logger[E]) AsHandler() Handler[E] {
+	return &loggerHandler[E]{lg: lg}
+}
+
+// allHandlers returns every Handler in lg, without duplicates.
+func (lg Logger[E]) allHandlers() []Handler[E] {
+	(*lg.mtx).Lock()
+	defer (*lg.mtx).Unlock()
+
+	var hs []Handler[E]
+	for _, entries := range lg.h {
+		for _, entry := range entries {
+			hs = appendUniqueHandler(hs, entry.h)
+		}
+	}
+	return hs
+}
+
+// drainWorkers waits for the events queued by parallel dispatch to reach lg's
+// Handlers, so that they are there to be flushed. If stop is set, the
+// goroutines that pass them on are also stopped.
+func (lg Logger[E]) drainWorkers(ctx context.Context, stop bool) error {
+	(*lg.mtx).Lock()
+	var ws []*dispatchWorker[E]
+	for _, entries := range lg.h {
+		ws = append(ws, entryWorkers(entries)...)
+	}
+	(*lg.mtx).Unlock()
+
+	var fullErr error
+	for _, w := range ws {
+		fullErr = joinHandlerErr(fullErr, w.drain(ctx, stop))
+	}
+	return fullErr
+}
+
+// loggerHandler is the Handler returned by Logger.AsHandler.
+type loggerHandler[E any] struct {
+	lg Logger[E]
+}
+
+func (lh *loggerHandler[E]) HandlerOptions() HandlerOptions[E] {
+	return lh.lg.HandlerOptions()
+}
+
+func (lh *loggerHandler[E]) Output(calldepth int, evt Event[E]) error {
+	return lh.lg.Output(calldepth+1, evt)
+}
+
+func (lh *loggerHandler[E]) InsertBreak() error {
+	return lh.lg.InsertBreak(LvAll)
+}
+
+func (lh *loggerHandler[E]) WrappedHandlers() []Handler[E] {
+	return lh.lg.allHandlers()
+}
+
+// Flush waits for events queued by the Logger's parallel dispatch to reach its
+// Handlers. The Handlers themselves are reached separately as the Handlers
+// that lh wraps.
+func (lh *loggerHandler[E]) Flush(ctx context.Context) error {
+	return lh.lg.drainWorkers(ctx, false)
+}
+
+// Close is the same as Flush, but also stops the goroutines used by the
+// Logger's parallel dispatch.
+func (lh *loggerHandler[E]) Close(ctx context.Context) error {
+	return lh.lg.drainWorkers(ctx, true)
+}
+
+// walkHandlers flushes, or closes if closing is set, every Handler reachable
+// from roots, as described in FlushHandlers and CloseHandlers.
+func walkHandlers[E any](ctx context.Context, roots []Handler[E], closing bool) error {
+	var fullErr error
+	for _, h := range handlerOrder(roots) {
+		if err := ctx.Err(); err != nil {
+			return joinHandlerErr(fullErr, err)
+		}
+
+		if c, ok := h.(Closer); ok && closing {
+			fullErr = joinHandlerErr(fullErr, c.Close(ctx))
+		} else if f, ok := h.(Flusher); ok {
+			fullErr = joinHandlerErr(fullErr, f.Flush(ctx))
+		}
+	}
+	return fullErr
+}
+
+// handlerOrder returns every Handler reachable from roots exactly once, with
+// each Handler placed after every Handler that wraps it. If wrapping Handlers
+// form a cycle, the Handlers in it are placed in the order they were found.
+// Handlers are told apart with sameHandler, so a Handler that is not
+// comparable is treated as a different Handler each time it is reached.
+func handlerOrder[E any](roots []Handler[E]) []Handler[E] {
+	var found []Handler[E]
+	var parents []int
+	var children [][]int
+
+	indexOf := func(h Handler[E]) int {
+		for i := range found {
+			if sameHandler(found[i], h) {
+				return i
+			}
+		}
+		return -1
+	}
+
+	var visit func(h Handler[E]) int
+	visit = func(h Handler[E]) int {
+		if i := indexOf(h); i >= 0 {
+			return i
+		}
+		idx := len(found)
+		found = append(found, h)
+		parents = append(parents, 0)
+		children = append(children, nil)
+
+		if hw, ok := h.(HandlerWrapper[E]); ok {
+			var wrapped []Handler[E]
+			for _, child := range hw.WrappedHandlers() {
+				if child != nil {
+					wrapped = appendUniqueHandler(wrapped, child)
+				}
+			}
+			for _, child := range wrapped {
+				c := visit(child)
+				children[idx] = append(children[idx], c)
+			}
+		}
+		return idx
+	}
+	for _, h := range roots {
+		visit(h)
+	}
+	for i := range found {
+		for _, c := range children[i] {
+			parents[c]++
+		}
+	}
+
+	ordered := make([]Handler[E], 0, len(found))
+	placed := make([]bool, len(found))
+	for len(ordered) < len(found) {
+		progress := false
+		for i := range found {
+			if placed[i] || parents[i] > 0 {
+				continue
+			}
+			placed[i] = true
+			ordered = append(ordered, found[i])
+			for _, c := range children[i] {
+				parents[c]--
+			}
+			progress = true
+		}
+
+		if !progress {
+			// cycle; break it by placing the first remaining Handler
+			for i := range found {
+				if !placed[i] {
+					parents[i] = 0
+					break
+				}
+			}
+		}
+	}
+	return ordered
+}
+
+// sameHandler returns whether a and b are the same Handler. Handlers are
+// compared with ==, except that a Handler whose type is not comparable, such
+// as a struct value containing a slice or map, is never the same as any
+// other, as comparing it would panic.
+func sameHandler[E any](a, b Handler[E]) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	if !reflect.TypeOf(a).Comparable() || !reflect.TypeOf(b).Comparable() {
+		return false
+	}
+	return a == b
+}
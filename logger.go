@@ -1,8 +1,9 @@
 package jellog
 
 import (
+	"errors"
 	"fmt"
-	"os"
+	"strings"
 	"sync"
 	"time"
 )
@@ -23,36 +24,44 @@ type Logger[E any] struct {
 
 	useMtxForLogging bool
 
-	mtx *sync.Mutex
-	h   map[int][]Handler[E]
+	mtx     *sync.Mutex
+	h       map[int][]handLv[E]
+	errs    *errorState
+	compLv  map[string]Level
+	workers *dispatchWorkers[E]
 }
 
 // New creates a new Logger with the given Options. For the standard default
 // options, call Defaults().
 func New[E any](opts Options[E]) Logger[E] {
+	return newLogger(opts, &dispatchWorkers[E]{})
+}
+
+// newLogger creates a new Logger with the given Options that takes the
+// dispatchWorkers of its Handlers from workers.
+func newLogger[E any](opts Options[E], workers *dispatchWorkers[E]) Logger[E] {
 	if opts.Converter == nil {
-		// iff E is string, then the default converter is fmt.Sprintf("%v", v)
-		var empty E
-		if _, isString := any(empty).(string); isString {
-			opts.Converter = func(v any) E {
-				return any(fmt.Sprintf("%v", v)).(E)
-			}
-		} else {
-			opts.Converter = func(v any) E {
-				var empty E
-				return empty
-			}
-		}
+		opts.Converter = defaultConverter[E]()
 	}
 
 	logger := Logger[E]{
-		h:    make(map[int][]Handler[E]),
+		h:    make(map[int][]handLv[E]),
 		opts: opts,
 		mtx:  new(sync.Mutex),
+		errs: &errorState{},
+
+		workers: workers,
 
 		useMtxForLogging: true,
 	}
 
+	if len(opts.ComponentLevels) > 0 {
+		logger.compLv = make(map[string]Level, len(opts.ComponentLevels))
+		for c, lv := range opts.ComponentLevels {
+			logger.compLv[c] = lv.resolved()
+		}
+	}
+
 	if len(opts.Handlers) > 0 {
 		for lv := range opts.Handlers {
 			for _, h := range opts.Handlers[lv] {
@@ -64,9 +73,27 @@ func New[E any](opts Options[E]) Logger[E] {
 	return logger
 }
 
+// defaultConverter returns the converter used when none is given in Options.
+func defaultConverter[E any]() func(v any) E {
+	// iff E is string, then the default converter is fmt.Sprintf("%v", v)
+	var empty E
+	if _, isString := any(empty).(string); isString {
+		return func(v any) E {
+			return any(fmt.Sprintf("%v", resolveLogValue(v))).(E)
+		}
+	}
+	return func(v any) E {
+		var empty E
+		return empty
+	}
+}
+
+// handLv is a Handler along with the Level it was added to a Logger at and the
+// dispatchWorker that passes it events when ParallelDispatch is set.
 type handLv[E any] struct {
 	h  Handler[E]
-	lv int
+	lv Level
+	w  *dispatchWorker[E]
 }
 
 // Copy creates a new Logger identical to this one, including the same handlers,
@@ -82,7 +109,11 @@ type handLv[E any] struct {
 // Finally, any Handler that is in the original Logger but is also included in
 // the Options object will be configured in the new Logger at the level given by
 // the Options object. Handlers are checked against each other via pointer
-// comparison.
+// comparison; a Handler whose type is not comparable never matches another, so
+// it is carried over unchanged and any Handler in the Options is added.
+//
+// Handlers that are carried over from the current Logger keep the exact Level
+// they were added at, including custom levels.
 func (lg Logger[E]) Copy(opts Options[E]) Logger[E] {
 	// we already HAVE creation based on an options object; our reel task is to
 	// create the 'merged' options
@@ -100,21 +131,71 @@ func (lg Logger[E]) Copy(opts Options[E]) Logger[E] {
 		merged.Formatter = lg.opts.Formatter
 	}
 
+	merged.HonorForcedLevel = lg.opts.HonorForcedLevel
+	if opts.HonorForcedLevel || opts.honorForcedLevelSet {
+		merged.HonorForcedLevel = opts.HonorForcedLevel
+	}
+	merged.honorForcedLevelSet = opts.honorForcedLevelSet || lg.opts.honorForcedLevelSet
+
 	// logger-specific options part
 	merged.Converter = opts.Converter
 	if merged.Converter == nil {
 		merged.Converter = lg.opts.Converter
 	}
 
+	merged.Extractors = opts.Extractors
+	if merged.Extractors == nil {
+		merged.Extractors = lg.opts.Extractors
+	}
+
+	merged.Processors = opts.Processors
+	if merged.Processors == nil {
+		merged.Processors = lg.opts.Processors
+	}
+
+	merged.ErrorHandler = opts.ErrorHandler
+	if merged.ErrorHandler == nil {
+		merged.ErrorHandler = lg.opts.ErrorHandler
+	}
+
+	merged.ComponentLevels = opts.ComponentLevels
+	if merged.ComponentLevels == nil {
+		merged.ComponentLevels = lg.opts.ComponentLevels
+	}
+
+	merged.ExitFunc = opts.ExitFunc
+	if merged.ExitFunc == nil {
+		merged.ExitFunc = lg.opts.ExitFunc
+	}
+
+	merged.ExitTimeout = opts.ExitTimeout
+	if merged.ExitTimeout == 0 {
+		merged.ExitTimeout = lg.opts.ExitTimeout
+	}
+
+	merged.StackTraceLevel = opts.StackTraceLevel
+	if merged.StackTraceLevel == nil {
+		merged.StackTraceLevel = lg.opts.StackTraceLevel
+	}
+
+	merged.ParallelDispatch = lg.opts.ParallelDispatch
+	if opts.ParallelDispatch || opts.parallelDispatchSet {
+		merged.ParallelDispatch = opts.ParallelDispatch
+	}
+
+	merged.HandlerTimeout = opts.HandlerTimeout
+	if merged.HandlerTimeout == 0 && !opts.parallelDispatchSet {
+		merged.HandlerTimeout = lg.opts.HandlerTimeout
+	}
+	merged.parallelDispatchSet = opts.parallelDispatchSet || lg.opts.parallelDispatchSet
+
 	// tricky part - handlers
 
 	// first get all current handlers (protected)
 	(*lg.mtx).Lock()
 	current := []handLv[E]{}
-	for lv, hSlice := range lg.h {
-		for _, h := range hSlice {
-			current = append(current, handLv[E]{h: h, lv: lv})
-		}
+	for _, entries := range lg.h {
+		current = append(current, entries...)
 	}
 	(*lg.mtx).Unlock()
 
@@ -131,33 +212,16 @@ func (lg Logger[E]) Copy(opts Options[E]) Logger[E] {
 		// is the entry in the list of handlers being added?
 		var addedByOpt bool
 		for _, add := range optAdded {
-			if entry.h == add {
+			if sameHandler(entry.h, add) {
 				addedByOpt = true
 				break
 			}
 		}
 
 		if !addedByOpt {
-			lv := Level{Severity: entry.lv}
-			if entry.lv == minPossibleSeverity() {
-				lv = LvAll
-			} else if entry.lv == LvFatal.Severity {
-				lv = LvFatal
-			} else if entry.lv == LvError.Severity {
-				lv = LvError
-			} else if entry.lv == LvWarn.Severity {
-				lv = LvWarn
-			} else if entry.lv == LvInfo.Severity {
-				lv = LvInfo
-			} else if entry.lv == LvDebug.Severity {
-				lv = LvDebug
-			} else if entry.lv == LvTrace.Severity {
-				lv = LvTrace
-			}
-
-			curSlice := mergedHandlers[lv]
+			curSlice := mergedHandlers[entry.lv]
 			curSlice = append(curSlice, entry.h)
-			mergedHandlers[lv] = curSlice
+			mergedHandlers[entry.lv] = curSlice
 		}
 	}
 
@@ -172,7 +236,7 @@ func (lg Logger[E]) Copy(opts Options[E]) Logger[E] {
 		merged.Handlers = mergedHandlers
 	}
 
-	return New(merged)
+	return newLogger(merged, lg.workers)
 }
 
 // AddHandler adds the given Handler to the Logger and configures it to receive
@@ -181,10 +245,15 @@ func (lg Logger[E]) Copy(opts Options[E]) Logger[E] {
 // If LvAll or a Level that returns the same value for its Severity() method as
 // LvAll does is used as the level, then the handler will be configured to
 // receive all severities of errors.
+//
+// If lv has no name but its severity is that of a registered level, the
+// registered level is used in its place.
 func (lg *Logger[E]) AddHandler(lv Level, out Handler[E]) {
 	(*lg.mtx).Lock()
 	defer (*lg.mtx).Unlock()
 
+	lv = lv.resolved()
+
 	sev := lv.Severity
 	if lv.Severity == LvAll.Severity {
 		sev = minPossibleSeverity()
@@ -192,9 +261,9 @@ func (lg *Logger[E]) AddHandler(lv Level, out Handler[E]) {
 
 	currentList, ok := lg.h[sev]
 	if !ok {
-		currentList = make([]Handler[E], 0)
+		currentList = make([]handLv[E], 0)
 	}
-	currentList = append(currentList, out)
+	currentList = append(currentList, handLv[E]{h: out, lv: lv, w: lg.workers.forHandler(out)})
 	lg.h[sev] = currentList
 }
 
@@ -202,20 +271,18 @@ func (lg *Logger[E]) AddHandler(lv Level, out Handler[E]) {
 // varies based on the underlying log; for text-based logs, it is generally a
 // newline character.
 func (lg Logger[E]) InsertBreak(lv Level) error {
-	dispatch := lg.HandlersForLevel(lv)
+	dispatch := lg.entriesForLevel(lv)
 
 	var fullErr error
-	for i := range dispatch {
-		err := dispatch[i].InsertBreak()
-		if err != nil {
-			if fullErr != nil {
-				fullErr = fmt.Errorf("%s\nhander: %w", fullErr.Error(), err)
-			} else {
-				fullErr = fmt.Errorf("handler: %w", err)
-			}
+	if lg.opts.ParallelDispatch {
+		fullErr = dispatchParallel(entryWorkers(dispatch), lg.opts.HandlerTimeout, 0, Event[E]{}, true)
+	} else {
+		for i := range dispatch {
+			fullErr = joinHandlerErr(fullErr, dispatch[i].h.InsertBreak())
 		}
 	}
 
+	lg.errs.record(fullErr)
 	return fullErr
 }
 
@@ -232,7 +299,13 @@ func (lg Logger[E]) Options() Options[E] {
 }
 
 // Output dispatches a log event to the Handlers in lg that are configured to
-// revceive events of that level or lower.
+// revceive events of that level or lower. Before it is dispatched, the event is
+// passed through the Logger's Processors in order; if any of them drops it, it
+// is not dispatched at all and nil is returned.
+//
+// The errors returned by the Handlers are combined and returned. A non-nil
+// error is also recorded as the Logger's LastError, but it is not passed to
+// the Logger's ErrorHandler; use HandleError for that.
 //
 // The calldepth argument is used for recovering the program counter. It should
 // be supplied with the number of levels into the jellog package that the caller
@@ -246,20 +319,61 @@ func (lg Logger[E]) Output(calldepth int, evt Event[E]) error {
 		evt.Component += lg.opts.Component
 	}
 
-	dispatch := lg.HandlersForLevel(evt.Level)
+	if evt.PC == 0 {
+		evt.PC = callerPC(calldepth)
+	}
 
-	var fullErr error
-	for i := range dispatch {
-		err := dispatch[i].Output(calldepth+1, evt)
-		if err != nil {
-			if fullErr != nil {
-				fullErr = fmt.Errorf("%s\nhander: %w", fullErr.Error(), err)
-			} else {
-				fullErr = fmt.Errorf("handler: %w", err)
+	// give custom levels created from only a severity their registered name
+	evt.Level = evt.Level.resolved()
+
+	var onlyForced bool
+	if min, ok := lg.ComponentLevel(evt.Component); ok && evt.Level.Severity < min.Severity {
+		forced, isForced := ForcedLevel(evt.Context())
+		if !isForced || evt.Level.Severity < forced.Severity {
+			return nil
+		}
+		onlyForced = true
+	}
+
+	if evt.Stack == nil && lg.wantsStack(evt.Level) {
+		if evt.callers != nil {
+			evt.Stack = stackFrames(evt.callers)
+		} else {
+			evt.Stack = captureStack(calldepth)
+		}
+	}
+
+	evt, keep := runProcessors(lg.opts.Processors, evt)
+	if !keep {
+		return nil
+	}
+
+	dispatch := lg.entriesForEvent(evt)
+	if onlyForced {
+		var honoring []handLv[E]
+		for _, entry := range dispatch {
+			if entry.h.HandlerOptions().HonorForcedLevel {
+				honoring = append(honoring, entry)
 			}
 		}
+		dispatch = honoring
+	}
+
+	var fullErr error
+	if lg.opts.ParallelDispatch {
+		// the Handlers run on other goroutines, so any Logger among them must
+		// be given the stack rather than capturing it itself
+		if evt.Stack == nil && evt.callers == nil {
+			evt.callers = callerPCs(calldepth)
+		}
+		fullErr = dispatchParallel(entryWorkers(dispatch), lg.opts.HandlerTimeout, calldepth+1, evt, false)
+	} else {
+		for i := range dispatch {
+			fullErr = joinHandlerErr(fullErr, dispatch[i].h.Output(calldepth+1, evt))
+		}
 	}
 
+	lg.errs.record(fullErr)
 	return fullErr
 }
 
@@ -270,8 +384,7 @@ func (lg Logger[E]) Output(calldepth int, evt Event[E]) error {
 // If msg is of type E, then it is used directly. If it is not, it is converted
 // to the proper type by using the Logger's Converter function.
 func (lg Logger[E]) Log(lv Level, msg any) {
-	evt := lg.CreateEvent(lv, msg)
-	lg.Output(2, evt)
+	lg.log(2, lv, msg)
 }
 
 // Logf logs a formatted message at the given severity level. Supplementary
@@ -282,8 +395,18 @@ func (lg Logger[E]) Log(lv Level, msg any) {
 // type of logged object handled by the Logger by using the Logger's Converter
 // function.
 func (lg Logger[E]) Logf(lv Level, msg string, a ...interface{}) {
-	evt := lg.CreateEvent(lv, fmt.Sprintf(msg, a...))
-	lg.Output(2, evt)
+	lg.log(2, lv, fmt.Sprintf(msg, a...))
+}
+
+// Logln logs a message at the given severity level. Supplementary information
+// is gathered along with the message into an Event which is then passed to the
+// appropriate Handlers.
+//
+// The message is created from v in the manner of fmt.Println, and is then
+// converted to the type of logged object handled by the Logger by using the
+// Logger's Converter function.
+func (lg Logger[E]) Logln(lv Level, v ...any) {
+	lg.log(2, lv, fmt.Sprintln(v...))
 }
 
 // Trace logs a message at severity level TRACE. Supplementary information is
@@ -293,8 +416,7 @@ func (lg Logger[E]) Logf(lv Level, msg string, a ...interface{}) {
 // If msg is of type E, then it is used directly. If it is not, it is converted
 // to the proper type by using the Logger's Converter function.
 func (lg Logger[E]) Trace(msg E) {
-	evt := lg.CreateEvent(LvTrace, msg)
-	lg.Output(2, evt)
+	lg.log(2, LvTrace, msg)
 }
 
 // Tracef logs a formatted message at severity level TRACE. Supplementary
@@ -305,8 +427,18 @@ func (lg Logger[E]) Trace(msg E) {
 // type of logged object handled by the Logger by using the Logger's Converter
 // function.
 func (lg Logger[E]) Tracef(msg string, a ...interface{}) {
-	evt := lg.CreateEvent(LvTrace, fmt.Sprintf(msg, a...))
-	lg.Output(2, evt)
+	lg.log(2, LvTrace, fmt.Sprintf(msg, a...))
+}
+
+// Traceln logs a message at severity level TRACE. Supplementary information
+// is gathered along with the message into an Event which is then passed to the
+// appropriate Handlers.
+//
+// The message is created from v in the manner of fmt.Println, and is then
+// converted to the type of logged object handled by the Logger by using the
+// Logger's Converter function.
+func (lg Logger[E]) Traceln(v ...any) {
+	lg.log(2, LvTrace, fmt.Sprintln(v...))
 }
 
 // Debug logs a message at severity level DEBUG. Supplementary information is
@@ -316,8 +448,7 @@ func (lg Logger[E]) Tracef(msg string, a ...interface{}) {
 // If msg is of type E, then it is used directly. If it is not, it is converted
 // to the proper type by using the Logger's Converter function.
 func (lg Logger[E]) Debug(msg E) {
-	evt := lg.CreateEvent(LvDebug, msg)
-	lg.Output(2, evt)
+	lg.log(2, LvDebug, msg)
 }
 
 // Debugf logs a formatted message at severity level DEBUG. Supplementary
@@ -328,8 +459,18 @@ func (lg Logger[E]) Debug(msg E) {
 // type of logged object handled by the Logger by using the Logger's Converter
 // function.
 func (lg Logger[E]) Debugf(msg string, a ...interface{}) {
-	evt := lg.CreateEvent(LvDebug, fmt.Sprintf(msg, a...))
-	lg.Output(2, evt)
+	lg.log(2, LvDebug, fmt.Sprintf(msg, a...))
+}
+
+// Debugln logs a message at severity level DEBUG. Supplementary information
+// is gathered along with the message into an Event which is then passed to the
+// appropriate Handlers.
+//
+// The message is created from v in the manner of fmt.Println, and is then
+// converted to the type of logged object handled by the Logger by using the
+// Logger's Converter function.
+func (lg Logger[E]) Debugln(v ...any) {
+	lg.log(2, LvDebug, fmt.Sprintln(v...))
 }
 
 // Info logs a message at severity level INFO. Supplementary information is
@@ -339,8 +480,7 @@ func (lg Logger[E]) Debugf(msg string, a ...interface{}) {
 // If msg is of type E, then it is used directly. If it is not, it is converted
 // to the proper type by using the Logger's Converter function.
 func (lg Logger[E]) Info(msg E) {
-	evt := lg.CreateEvent(LvInfo, msg)
-	lg.Output(2, evt)
+	lg.log(2, LvInfo, msg)
 }
 
 // Infof logs a formatted message at severity level INFO. Supplementary
@@ -351,8 +491,18 @@ func (lg Logger[E]) Info(msg E) {
 // type of logged object handled by the Logger by using the Logger's Converter
 // function.
 func (lg Logger[E]) Infof(msg string, a ...interface{}) {
-	evt := lg.CreateEvent(LvInfo, fmt.Sprintf(msg, a...))
-	lg.Output(2, evt)
+	lg.log(2, LvInfo, fmt.Sprintf(msg, a...))
+}
+
+// Infoln logs a message at severity level INFO. Supplementary information
+// is gathered along with the message into an Event which is then passed to the
+// appropriate Handlers.
+//
+// The message is created from v in the manner of fmt.Println, and is then
+// converted to the type of logged object handled by the Logger by using the
+// Logger's Converter function.
+func (lg Logger[E]) Infoln(v ...any) {
+	lg.log(2, LvInfo, fmt.Sprintln(v...))
 }
 
 // Warn logs a message at severity level WARN. Supplementary information is
@@ -362,8 +512,7 @@ func (lg Logger[E]) Infof(msg string, a ...interface{}) {
 // If msg is of type E, then it is used directly. If it is not, it is converted
 // to the proper type by using the Logger's Converter function.
 func (lg Logger[E]) Warn(msg E) {
-	evt := lg.CreateEvent(LvWarn, msg)
-	lg.Output(2, evt)
+	lg.log(2, LvWarn, msg)
 }
 
 // Warnf logs a formatted message at severity level WARN. Supplementary
@@ -374,8 +523,18 @@ func (lg Logger[E]) Warn(msg E) {
 // type of logged object handled by the Logger by using the Logger's Converter
 // function.
 func (lg Logger[E]) Warnf(msg string, a ...interface{}) {
-	evt := lg.CreateEvent(LvWarn, fmt.Sprintf(msg, a...))
-	lg.Output(2, evt)
+	lg.log(2, LvWarn, fmt.Sprintf(msg, a...))
+}
+
+// Warnln logs a message at severity level WARN. Supplementary information
+// is gathered along with the message into an Event which is then passed to the
+// appropriate Handlers.
+//
+// The message is created from v in the manner of fmt.Println, and is then
+// converted to the type of logged object handled by the Logger by using the
+// Logger's Converter function.
+func (lg Logger[E]) Warnln(v ...any) {
+	lg.log(2, LvWarn, fmt.Sprintln(v...))
 }
 
 // Error logs a message at severity level ERROR. Supplementary information is
@@ -385,8 +544,7 @@ func (lg Logger[E]) Warnf(msg string, a ...interface{}) {
 // If msg is of type E, then it is used directly. If it is not, it is converted
 // to the proper type by using the Logger's Converter function.
 func (lg Logger[E]) Error(msg E) {
-	evt := lg.CreateEvent(LvError, msg)
-	lg.Output(2, evt)
+	lg.log(2, LvError, msg)
 }
 
 // Errorf logs a formatted message at severity level ERROR. Supplementary
@@ -397,54 +555,243 @@ func (lg Logger[E]) Error(msg E) {
 // type of logged object handled by the Logger by using the Logger's Converter
 // function.
 func (lg Logger[E]) Errorf(msg string, a ...interface{}) {
-	evt := lg.CreateEvent(LvError, fmt.Sprintf(msg, a...))
-	lg.Output(2, evt)
+	lg.log(2, LvError, fmt.Sprintf(msg, a...))
+}
+
+// Errorln logs a message at severity level ERROR. Supplementary information
+// is gathered along with the message into an Event which is then passed to the
+// appropriate Handlers.
+//
+// The message is created from v in the manner of fmt.Println, and is then
+// converted to the type of logged object handled by the Logger by using the
+// Logger's Converter function.
+func (lg Logger[E]) Errorln(v ...any) {
+	lg.log(2, LvError, fmt.Sprintln(v...))
 }
 
-// Fatal logs a message at severity level FATAL and then exits the program.
-// Supplementary information is gathered along with msg into an Event which is
-// then passed to the appropriate Handlers.
+// Fatal logs a message at severity level FATAL and then exits the program with
+// status code 1 as described in Exit. Supplementary information is gathered
+// along with msg into an Event which is then passed to the appropriate
+// Handlers.
 //
 // If msg is of type E, then it is used directly. If it is not, it is converted
 // to the proper type by using the Logger's Converter function.
 func (lg Logger[E]) Fatal(msg E) {
-	evt := lg.CreateEvent(LvFatal, msg)
-	lg.Output(2, evt)
-	os.Exit(1)
+	lg.fatal(2, 1, msg)
 }
 
 // Fatalf logs a formatted message at severity level FATAL and then exits the
-// program. Supplementary information is gathered along with msg into an Event
-// which is then passed to the appropriate Handlers.
+// program with status code 1 as described in Exit. Supplementary information is
+// gathered along with msg into an Event which is then passed to the appropriate
+// Handlers.
 //
 // The message is created as a formatted string, and is then converted to the
 // type of logged object handled by the Logger by using the Logger's Converter
 // function.
 func (lg Logger[E]) Fatalf(msg string, a ...interface{}) {
-	evt := lg.CreateEvent(LvFatal, fmt.Sprintf(msg, a...))
-	lg.Output(2, evt)
-	os.Exit(1)
+	lg.fatal(2, 1, fmt.Sprintf(msg, a...))
+}
+
+// Fatalln logs a message at severity level FATAL and then exits the program
+// with status code 1 as described in Exit. The message is created from v in the
+// manner of fmt.Println, and is then converted to the type of logged object
+// handled by the Logger by using the Logger's Converter function.
+func (lg Logger[E]) Fatalln(v ...any) {
+	lg.fatal(2, 1, fmt.Sprintln(v...))
+}
+
+// Print logs a message at severity level INFO. The message is created from v
+// in the manner of fmt.Print, and is then converted to the type of logged
+// object handled by the Logger by using the Logger's Converter function.
+//
+// This method is included for compatibility with the built-in log package.
+func (lg Logger[E]) Print(v ...any) {
+	lg.log(2, LvInfo, fmt.Sprint(v...))
+}
+
+// Printf logs a message at severity level INFO. It is equivalent to Infof().
+// The message is created from format and v in the manner of fmt.Printf, and is
+// then converted to the type of logged object handled by the Logger by using
+// the Logger's Converter function.
+//
+// This method is included for compatibility with the built-in log package.
+func (lg Logger[E]) Printf(format string, v ...any) {
+	lg.log(2, LvInfo, fmt.Sprintf(format, v...))
+}
+
+// Println logs a message at severity level INFO. It is equivalent to Infoln().
+// The message is created from v in the manner of fmt.Println, and is then
+// converted to the type of logged object handled by the Logger by using the
+// Logger's Converter function.
+//
+// This method is included for compatibility with the built-in log package.
+func (lg Logger[E]) Println(v ...any) {
+	lg.log(2, LvInfo, fmt.Sprintln(v...))
+}
+
+// Panic logs a message at severity level FATAL and then calls panic() with the
+// message as its argument. The message is created from v in the manner of
+// fmt.Print, and is then converted to the type of logged object handled by the
+// Logger by using the Logger's Converter function; the value given to panic()
+// is the unconverted string.
+//
+// This method is included for compatibility with the built-in log package.
+func (lg Logger[E]) Panic(v ...any) {
+	lg.panic(2, fmt.Sprint(v...))
+}
+
+// Panicf logs a message at severity level FATAL and then calls panic() with
+// the message as its argument. The message is created from format and v in the
+// manner of fmt.Printf, and is then converted to the type of logged object
+// handled by the Logger by using the Logger's Converter function; the value
+// given to panic() is the unconverted string.
+//
+// This method is included for compatibility with the built-in log package.
+func (lg Logger[E]) Panicf(format string, v ...any) {
+	lg.panic(2, fmt.Sprintf(format, v...))
+}
+
+// Panicln logs a message at severity level FATAL and then calls panic() with
+// the message as its argument. The message is created from v in the manner of
+// fmt.Println, and is then converted to the type of logged object handled by
+// the Logger by using the Logger's Converter function; the value given to
+// panic() is the unconverted string.
+//
+// This method is included for compatibility with the built-in log package.
+func (lg Logger[E]) Panicln(v ...any) {
+	lg.panic(2, fmt.Sprintln(v...))
+}
+
+// log creates an event from msg and outputs it, passing any error to the
+// Logger's error policy. It is the shared implementation of the logging
+// methods of Logger and the package-level logging functions, which call it
+// with a calldepth of 2.
+func (lg Logger[E]) log(calldepth int, lv Level, msg any) {
+	evt := lg.CreateEvent(lv, msg)
+	lg.HandleError(lg.Output(calldepth+1, evt))
+}
+
+// fatal logs msg at level FATAL as log does and then exits with the given
+// code.
+func (lg Logger[E]) fatal(calldepth int, code int, msg any) {
+	lg.log(calldepth+1, LvFatal, msg)
+	lg.Exit(code)
+}
+
+// panic logs msg at level FATAL as log does and then panics with it.
+func (lg Logger[E]) panic(calldepth int, msg string) {
+	lg.log(calldepth+1, LvFatal, msg)
+	panic(msg)
 }
 
 // HandlersForLevel returns all Handlers added to the Logger that are configured
 // to be able to receive log events at the given level.
 func (lg Logger[E]) HandlersForLevel(lv Level) []Handler[E] {
+	return entryHandlers(lg.entriesForLevel(lv))
+}
+
+// HandlersForEvent returns all Handlers added to the Logger that should receive
+// the given event. This is all Handlers that are configured to receive events
+// at the event's level, as with HandlersForLevel, as well as any Handlers with
+// HandlerOptions.HonorForcedLevel set if the event was logged with a context
+// created by WithForcedLevel and it is at or above the forced level.
+func (lg Logger[E]) HandlersForEvent(evt Event[E]) []Handler[E] {
+	return entryHandlers(lg.entriesForEvent(evt))
+}
+
+// entriesForLevel returns the entries of the Handlers that HandlersForLevel
+// returns.
+func (lg Logger[E]) entriesForLevel(lv Level) []handLv[E] {
 	(*lg.mtx).Lock()
 	defer (*lg.mtx).Unlock()
 
-	var outputs []Handler[E]
+	var outputs []handLv[E]
 
 	// this could be more efficient if instead of a map we used a priority-based
 	// system. then again, not shore there will rly be THAT many outputs
-	for minLevel := range lg.h {
+	for minLevel, entries := range lg.h {
 		if minLevel <= lv.Severity {
-			outputs = append(outputs, lg.h[minLevel]...)
+			outputs = append(outputs, entries...)
 		}
 	}
 
 	return outputs
 }
 
+// entriesForEvent returns the entries of the Handlers that HandlersForEvent
+// returns.
+func (lg Logger[E]) entriesForEvent(evt Event[E]) []handLv[E] {
+	forced, isForced := ForcedLevel(evt.Context())
+	if !isForced || evt.Level.Severity < forced.Severity {
+		return lg.entriesForLevel(evt.Level)
+	}
+
+	(*lg.mtx).Lock()
+	defer (*lg.mtx).Unlock()
+
+	var outputs []handLv[E]
+	for minLevel, entries := range lg.h {
+		for _, entry := range entries {
+			if minLevel <= evt.Level.Severity || entry.h.HandlerOptions().HonorForcedLevel {
+				outputs = append(outputs, entry)
+			}
+		}
+	}
+
+	return outputs
+}
+
+// entryHandlers returns the Handler of each of entries.
+func entryHandlers[E any](entries []handLv[E]) []Handler[E] {
+	var hs []Handler[E]
+	for _, entry := range entries {
+		hs = append(hs, entry.h)
+	}
+	return hs
+}
+
+// entryWorkers returns the dispatchWorker of each of entries.
+func entryWorkers[E any](entries []handLv[E]) []*dispatchWorker[E] {
+	ws := make([]*dispatchWorker[E], len(entries))
+	for i := range entries {
+		ws[i] = entries[i].w
+	}
+	return ws
+}
+
+// ComponentLevel returns the minimum level that the Logger's ComponentLevels
+// option sets for events from component c, and whether it sets one at all.
+func (lg Logger[E]) ComponentLevel(c string) (Level, bool) {
+	if len(lg.compLv) == 0 {
+		return Level{}, false
+	}
+
+	for {
+		if lv, ok := lg.compLv[c]; ok {
+			return lv, true
+		}
+		if c == "" {
+			return Level{}, false
+		}
+
+		dot := strings.LastIndexByte(c, '.')
+		if dot < 0 {
+			c = ""
+		} else {
+			c = c[:dot]
+		}
+	}
+}
+
+// wantsStack returns whether events at level lv should have a stack trace
+// captured, according to the Logger's StackTraceLevel option.
+func (lg Logger[E]) wantsStack(lv Level) bool {
+	if lg.opts.StackTraceLevel == nil {
+		return false
+	}
+	return lv.Severity >= lg.opts.StackTraceLevel.Severity
+}
+
 // CreateEvent creates an Event of the appropriate type using msg. The new Event
 // will have the current time, level, component, and any other attributes
 // configured as part of the Logger for Event creation. The msg will be
@@ -464,9 +811,70 @@ func (lg Logger[E]) CreateEvent(lv Level, msg any) Event[E] {
 		Time:      now,
 		Level:     lv,
 		Component: "", // will be auto-filled by using event with Logger.Output
+		Converted: !isEType,
 
 		Message: typedMsg,
 	}
 
 	return evt
 }
+
+// joinHandlerErr adds err, returned from a Handler, to fullErr, the combined
+// error of all Handlers called so far. If err is nil, fullErr is returned
+// unchanged. Every error that is added can be found with errors.Is and
+// errors.As.
+func joinHandlerErr(fullErr, err error) error {
+	if err == nil {
+		return fullErr
+	}
+	if joined, ok := fullErr.(*handlerErrors); ok {
+		errs := append(append(make([]error, 0, len(joined.errs)+1), joined.errs...), err)
+		return &handlerErrors{errs: errs}
+	}
+	if fullErr != nil {
+		return &handlerErrors{errs: []error{fullErr, err}}
+	}
+	return &handlerErrors{errs: []error{err}}
+}
+
+// handlerErrors is the combined error of one or more Handlers. Its message has
+// a line for each error, and it unwraps to the last of them as the combined
+// error always has. So that none of them are hidden from errors.Is and
+// errors.As, it also checks each of the others itself.
+type handlerErrors struct {
+	errs []error
+}
+
+func (e *handlerErrors) Error() string {
+	var sb strings.Builder
+	for i, err := range e.errs {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		sb.WriteString("handler: ")
+		sb.WriteString(err.Error())
+	}
+	return sb.String()
+}
+
+func (e *handlerErrors) Unwrap() error {
+	return e.errs[len(e.errs)-1]
+}
+
+func (e *handlerErrors) Is(target error) bool {
+	for _, err := range e.errs[:len(e.errs)-1] {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *handlerErrors) As(target any) bool {
+	for _, err := range e.errs[:len(e.errs)-1] {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,481 @@
+package jellog_test
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dekarrin/jellog"
+	"github.com/dekarrin/jellog/jellogtest"
+)
+
+func Test_Logger_Copy_honorForcedLevel(t *testing.T) {
+	testCases := []struct {
+		name   string
+		base   bool
+		opts   jellog.Options[string]
+		expect bool
+	}{
+		{name: "unset keeps false", base: false, opts: jellog.Options[string]{}, expect: false},
+		{name: "unset keeps true", base: true, opts: jellog.Options[string]{}, expect: true},
+		{name: "true turns on", base: false, opts: jellog.Options[string]{}.WithHonorForcedLevel(true), expect: true},
+		{name: "field set to true turns on", base: false, opts: jellog.Options[string]{HandlerOptions: jellog.HandlerOptions[string]{HonorForcedLevel: true}}, expect: true},
+		{name: "explicit false turns off", base: true, opts: jellog.Options[string]{}.WithHonorForcedLevel(false), expect: false},
+		{name: "explicit false on defaults turns off", base: true, opts: jellog.Defaults[string]().WithHonorForcedLevel(false), expect: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			base := jellog.New(jellog.Defaults[string]().WithHonorForcedLevel(tc.base))
+
+			cp := base.Copy(tc.opts)
+
+			if actual := cp.Options().HonorForcedLevel; actual != tc.expect {
+				t.Errorf("expected HonorForcedLevel to be %v, got %v", tc.expect, actual)
+			}
+
+			// and a copy of the copy keeps what the copy has
+			if actual := cp.Copy(jellog.Options[string]{}).Options().HonorForcedLevel; actual != tc.expect {
+				t.Errorf("expected HonorForcedLevel of second copy to be %v, got %v", tc.expect, actual)
+			}
+		})
+	}
+}
+
+func Test_Logger_Copy_parallelDispatch(t *testing.T) {
+	testCases := []struct {
+		name          string
+		base          jellog.Options[string]
+		opts          jellog.Options[string]
+		expect        bool
+		expectTimeout time.Duration
+	}{
+		{
+			name:          "unset keeps parallel",
+			base:          jellog.Defaults[string]().WithParallelDispatch(true, time.Second),
+			opts:          jellog.Options[string]{},
+			expect:        true,
+			expectTimeout: time.Second,
+		},
+		{
+			name:   "unset keeps sequential",
+			base:   jellog.Defaults[string](),
+			opts:   jellog.Options[string]{},
+			expect: false,
+		},
+		{
+			name:          "turn on",
+			base:          jellog.Defaults[string](),
+			opts:          jellog.Defaults[string]().WithParallelDispatch(true, time.Minute),
+			expect:        true,
+			expectTimeout: time.Minute,
+		},
+		{
+			name:          "field set to true turns on and keeps timeout",
+			base:          jellog.Options[string]{HandlerTimeout: time.Second},
+			opts:          jellog.Options[string]{ParallelDispatch: true},
+			expect:        true,
+			expectTimeout: time.Second,
+		},
+		{
+			name:   "explicit false turns off",
+			base:   jellog.Defaults[string]().WithParallelDispatch(true, time.Second),
+			opts:   jellog.Defaults[string]().WithParallelDispatch(false, 0),
+			expect: false,
+		},
+		{
+			name:          "explicit timeout of 0 removes timeout",
+			base:          jellog.Defaults[string]().WithParallelDispatch(true, time.Second),
+			opts:          jellog.Defaults[string]().WithParallelDispatch(true, 0),
+			expect:        true,
+			expectTimeout: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			base := jellog.New(tc.base)
+
+			actual := base.Copy(tc.opts).Options()
+
+			if actual.ParallelDispatch != tc.expect {
+				t.Errorf("expected ParallelDispatch to be %v, got %v", tc.expect, actual.ParallelDispatch)
+			}
+			if actual.HandlerTimeout != tc.expectTimeout {
+				t.Errorf("expected HandlerTimeout to be %v, got %v", tc.expectTimeout, actual.HandlerTimeout)
+			}
+		})
+	}
+}
+
+// tagHandler is a Handler that is a struct value holding a slice, so it cannot
+// be compared with ==.
+type tagHandler struct {
+	rec  *jellogtest.Recorder[string]
+	tags []string
+}
+
+func (th tagHandler) HandlerOptions() jellog.HandlerOptions[string] {
+	return th.rec.HandlerOptions()
+}
+
+func (th tagHandler) Output(calldepth int, evt jellog.Event[string]) error {
+	return th.rec.Output(calldepth+1, evt)
+}
+
+func (th tagHandler) InsertBreak() error {
+	return th.rec.InsertBreak()
+}
+
+func Test_Logger_Copy_nonComparableHandler(t *testing.T) {
+	th := tagHandler{rec: jellogtest.NewRecorder[string](nil), tags: []string{"a"}}
+	lg := jellog.New(jellog.Defaults[string]())
+	lg.AddHandler(jellog.LvInfo, th)
+
+	// adding the handler through the options must not panic; as it cannot be
+	// matched, both the original entry and the new one are kept
+	cp := lg.Copy(jellog.Options[string]{}.WithHandler(jellog.LvTrace, th))
+	cp.Info("hello")
+
+	if n := th.rec.Len(); n != 2 {
+		t.Errorf("expected 2 events, got %d", n)
+	}
+	if n := len(cp.HandlersForLevel(jellog.LvTrace)); n != 1 {
+		t.Errorf("expected 1 handler for TRACE, got %d", n)
+	}
+}
+
+func Test_Logger_Output_handlerErrors(t *testing.T) {
+	errA := errors.New("disk full")
+	errB := &fs.PathError{Op: "write", Path: "/var/log/app.log", Err: fs.ErrPermission}
+
+	testCases := []struct {
+		name         string
+		errs         []error
+		expectText   string
+		expectUnwrap error
+	}{
+		{
+			name:         "one handler fails",
+			errs:         []error{nil, errA, nil},
+			expectText:   "handler: disk full",
+			expectUnwrap: errA,
+		},
+		{
+			name:         "two handlers fail",
+			errs:         []error{errA, nil, errB},
+			expectText:   "handler: disk full\nhandler: write /var/log/app.log: permission denied",
+			expectUnwrap: errB,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			lg := jellog.New(jellog.Defaults[string]())
+			for _, err := range tc.errs {
+				h := &failingHandler{Recorder: jellogtest.NewRecorder[string](nil), fail: jellogtest.All[string](), err: err}
+				if err == nil {
+					h.fail = func(jellog.Event[string]) bool { return false }
+				}
+				lg.AddHandler(jellog.LvTrace, h)
+			}
+
+			err := lg.Output(1, lg.CreateEvent(jellog.LvInfo, "hello"))
+
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+			if err.Error() != tc.expectText {
+				t.Errorf("expected error text %q, got %q", tc.expectText, err.Error())
+			}
+			if unwrapped := errors.Unwrap(err); unwrapped != tc.expectUnwrap {
+				t.Errorf("expected error to unwrap to %v, got %v", tc.expectUnwrap, unwrapped)
+			}
+			for _, expect := range tc.errs {
+				if expect != nil && !errors.Is(err, expect) {
+					t.Errorf("expected errors.Is to find %v", expect)
+				}
+			}
+			var pathErr *fs.PathError
+			if errors.As(err, &pathErr) != (tc.expectUnwrap == errB) {
+				t.Errorf("expected errors.As to find *fs.PathError only if a handler returned one")
+			}
+		})
+	}
+}
+
+func Test_Logger_ComponentLevel(t *testing.T) {
+	levels := map[string]jellog.Level{
+		"":        jellog.LvWarn,
+		"db":      jellog.LvInfo,
+		"db.pool": jellog.LvTrace,
+		"http":    jellog.LvError,
+	}
+
+	testCases := []struct {
+		name      string
+		levels    map[string]jellog.Level
+		component string
+		expect    jellog.Level
+		expectOK  bool
+	}{
+		{name: "exact key", levels: levels, component: "db", expect: jellog.LvInfo, expectOK: true},
+		{name: "more specific key overrides parent", levels: levels, component: "db.pool", expect: jellog.LvTrace, expectOK: true},
+		{name: "inherits from parent", levels: levels, component: "db.query", expect: jellog.LvInfo, expectOK: true},
+		{name: "inherits from nearest ancestor", levels: levels, component: "db.pool.conn.idle", expect: jellog.LvTrace, expectOK: true},
+		{name: "unlisted falls back to root", levels: levels, component: "cache", expect: jellog.LvWarn, expectOK: true},
+		{name: "prefix is not a parent", levels: levels, component: "dbx", expect: jellog.LvWarn, expectOK: true},
+		{name: "no component uses root", levels: levels, component: "", expect: jellog.LvWarn, expectOK: true},
+		{name: "without root, unlisted is not set", levels: map[string]jellog.Level{"db": jellog.LvInfo}, component: "cache", expectOK: false},
+		{name: "without root, no component is not set", levels: map[string]jellog.Level{"db": jellog.LvInfo}, component: "", expectOK: false},
+		{name: "no levels", levels: nil, component: "db", expectOK: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			lg := jellog.New(jellog.Options[string]{ComponentLevels: tc.levels})
+
+			actual, ok := lg.ComponentLevel(tc.component)
+
+			if ok != tc.expectOK {
+				t.Fatalf("expected ok to be %v, got %v", tc.expectOK, ok)
+			}
+			if ok && actual.Severity != tc.expect.Severity {
+				t.Errorf("expected level %s, got %s", tc.expect, actual)
+			}
+		})
+	}
+}
+
+func Test_Logger_Output_componentLevels(t *testing.T) {
+	var processed []string
+	counter := func(evt jellog.Event[string]) (jellog.Event[string], bool) {
+		processed = append(processed, evt.Message)
+		return evt, true
+	}
+
+	rec := jellogtest.NewRecorder[string](nil)
+	opts := jellog.Defaults[string]().
+		WithComponentLevel("db", jellog.LvWarn).
+		WithComponentLevel("db.pool", jellog.LvDebug).
+		WithProcessor(counter)
+	lg := jellog.New(opts)
+	lg.AddHandler(jellog.LvTrace, rec)
+
+	db := lg.Copy(jellog.Options[string]{}.WithComponent("db"))
+	pool := lg.Copy(jellog.Options[string]{}.WithComponent("db.pool"))
+	query := lg.Copy(jellog.Options[string]{}.WithComponent("db.query"))
+
+	db.Info("db info")
+	db.Warn("db warn")
+	pool.Trace("pool trace")
+	pool.Debug("pool debug")
+	query.Info("query info")
+	query.Error("query error")
+	lg.Trace("root trace")
+
+	expect := []string{"db warn", "pool debug", "query error", "root trace"}
+	if actual := messages(rec.Events()); strings.Join(actual, "|") != strings.Join(expect, "|") {
+		t.Errorf("expected events %q, got %q", expect, actual)
+	}
+
+	// events are filtered before the processors see them
+	if strings.Join(processed, "|") != strings.Join(expect, "|") {
+		t.Errorf("expected processors to see only %q, got %q", expect, processed)
+	}
+}
+
+func Test_Logger_Output_componentLevels_root(t *testing.T) {
+	rec := jellogtest.NewRecorder[string](nil)
+	opts := jellog.Defaults[string]().
+		WithComponentLevel("", jellog.LvError).
+		WithComponentLevel("db", jellog.LvTrace)
+	lg := jellog.New(opts)
+	lg.AddHandler(jellog.LvTrace, rec)
+
+	lg.Warn("no component warn")
+	lg.Error("no component error")
+	lg.Copy(jellog.Options[string]{}.WithComponent("http")).Warn("http warn")
+	lg.Copy(jellog.Options[string]{}.WithComponent("db")).Trace("db trace")
+
+	expect := []string{"no component error", "db trace"}
+	if actual := messages(rec.Events()); strings.Join(actual, "|") != strings.Join(expect, "|") {
+		t.Errorf("expected events %q, got %q", expect, actual)
+	}
+}
+
+func Test_Logger_Copy_componentLevels(t *testing.T) {
+	base := jellog.New(jellog.Defaults[string]().WithComponentLevel("db", jellog.LvWarn))
+
+	inherited := base.Copy(jellog.Options[string]{})
+	if lv, ok := inherited.ComponentLevel("db"); !ok || lv.Severity != jellog.LvWarn.Severity {
+		t.Errorf("expected copy to inherit level WARN for db, got %s (%v)", lv, ok)
+	}
+
+	overridden := base.Copy(jellog.Options[string]{}.WithComponentLevel("http", jellog.LvError))
+	if _, ok := overridden.ComponentLevel("db"); ok {
+		t.Errorf("expected copy with its own ComponentLevels not to have the original's")
+	}
+	if lv, ok := overridden.ComponentLevel("http"); !ok || lv.Severity != jellog.LvError.Severity {
+		t.Errorf("expected copy to have level ERROR for http, got %s (%v)", lv, ok)
+	}
+}
+
+func Test_Logger_packageFunctionParity(t *testing.T) {
+	testCases := []struct {
+		name        string
+		method      func(lg jellog.Logger[string])
+		function    func()
+		expectLv    jellog.Level
+		expect      string
+		expectPanic bool
+	}{
+		{
+			name:     "Print",
+			method:   func(lg jellog.Logger[string]) { lg.Print("a", 1, 2, "b") },
+			function: func() { jellog.Print("a", 1, 2, "b") },
+			expectLv: jellog.LvInfo,
+			expect:   "a1 2b",
+		},
+		{
+			name:     "Printf",
+			method:   func(lg jellog.Logger[string]) { lg.Printf("a%d", 1) },
+			function: func() { jellog.Printf("a%d", 1) },
+			expectLv: jellog.LvInfo,
+			expect:   "a1",
+		},
+		{
+			name:     "Println",
+			method:   func(lg jellog.Logger[string]) { lg.Println("a", 1) },
+			function: func() { jellog.Println("a", 1) },
+			expectLv: jellog.LvInfo,
+			expect:   "a 1\n",
+		},
+		{
+			name:     "Logln",
+			method:   func(lg jellog.Logger[string]) { lg.Logln(jellog.LvWarn, "a", 1) },
+			function: func() { jellog.Logln(jellog.LvWarn, "a", 1) },
+			expectLv: jellog.LvWarn,
+			expect:   "a 1\n",
+		},
+		{
+			name:     "Traceln",
+			method:   func(lg jellog.Logger[string]) { lg.Traceln("a", 1) },
+			function: func() { jellog.Traceln("a", 1) },
+			expectLv: jellog.LvTrace,
+			expect:   "a 1\n",
+		},
+		{
+			name:     "Debugln",
+			method:   func(lg jellog.Logger[string]) { lg.Debugln("a", 1) },
+			function: func() { jellog.Debugln("a", 1) },
+			expectLv: jellog.LvDebug,
+			expect:   "a 1\n",
+		},
+		{
+			name:     "Infoln",
+			method:   func(lg jellog.Logger[string]) { lg.Infoln("a", 1) },
+			function: func() { jellog.Infoln("a", 1) },
+			expectLv: jellog.LvInfo,
+			expect:   "a 1\n",
+		},
+		{
+			name:     "Warnln",
+			method:   func(lg jellog.Logger[string]) { lg.Warnln("a", 1) },
+			function: func() { jellog.Warnln("a", 1) },
+			expectLv: jellog.LvWarn,
+			expect:   "a 1\n",
+		},
+		{
+			name:     "Errorln",
+			method:   func(lg jellog.Logger[string]) { lg.Errorln("a", 1) },
+			function: func() { jellog.Errorln("a", 1) },
+			expectLv: jellog.LvError,
+			expect:   "a 1\n",
+		},
+		{
+			name:        "Panic",
+			method:      func(lg jellog.Logger[string]) { lg.Panic("a", 1, 2, "b") },
+			function:    func() { jellog.Panic("a", 1, 2, "b") },
+			expectLv:    jellog.LvFatal,
+			expect:      "a1 2b",
+			expectPanic: true,
+		},
+		{
+			name:        "Panicf",
+			method:      func(lg jellog.Logger[string]) { lg.Panicf("a%d", 1) },
+			function:    func() { jellog.Panicf("a%d", 1) },
+			expectLv:    jellog.LvFatal,
+			expect:      "a1",
+			expectPanic: true,
+		},
+		{
+			name:        "Panicln",
+			method:      func(lg jellog.Logger[string]) { lg.Panicln("a", 1) },
+			function:    func() { jellog.Panicln("a", 1) },
+			expectLv:    jellog.LvFatal,
+			expect:      "a 1\n",
+			expectPanic: true,
+		},
+	}
+
+	// the default logger is shared, so each case adds its own recorder to it;
+	// the recorders of earlier cases keep receiving events but are not checked
+	// again
+	std := jellog.Default()
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			lgRec := jellogtest.NewRecorder[string](nil)
+			lg := jellog.New(jellog.Defaults[string]())
+			lg.AddHandler(jellog.LvTrace, lgRec)
+
+			stdRec := jellogtest.NewRecorder[string](nil)
+			std.AddHandler(jellog.LvTrace, stdRec)
+
+			calls := []struct {
+				what string
+				call func()
+				rec  *jellogtest.Recorder[string]
+			}{
+				{what: "Logger." + tc.name, call: func() { tc.method(lg) }, rec: lgRec},
+				{what: "jellog." + tc.name, call: tc.function, rec: stdRec},
+			}
+
+			for _, c := range calls {
+				panicked := callRecovering(c.call)
+
+				if tc.expectPanic && panicked != tc.expect {
+					t.Errorf("%s: expected panic with %q, got %#v", c.what, tc.expect, panicked)
+				} else if !tc.expectPanic && panicked != nil {
+					t.Errorf("%s: expected no panic, got %#v", c.what, panicked)
+				}
+
+				evts := c.rec.Events()
+				if len(evts) != 1 {
+					t.Errorf("%s: expected 1 event, got %d", c.what, len(evts))
+					continue
+				}
+				if evts[0].Level != tc.expectLv || evts[0].Message != tc.expect {
+					t.Errorf("%s: expected %v event %q, got %v event %q", c.what, tc.expectLv, tc.expect, evts[0].Level, evts[0].Message)
+				}
+				if file, _, _, _ := evts[0].Caller(); filepath.Base(file) != "logger_test.go" {
+					t.Errorf("%s: expected caller in logger_test.go, got %q", c.what, file)
+				}
+			}
+		})
+	}
+}
+
+// callRecovering calls f and returns the value it panicked with, or nil if it
+// did not panic.
+func callRecovering(f func()) (panicked any) {
+	defer func() {
+		panicked = recover()
+	}()
+	f()
+	return nil
+}
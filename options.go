@@ -1,5 +1,7 @@
 package jellog
 
+import "time"
+
 // HandlerOptions is used to control the behavior of Handlers. It is generally
 // passed to constructor functions as an optional argument.
 type HandlerOptions[E any] struct {
@@ -9,6 +11,19 @@ type HandlerOptions[E any] struct {
 	// Formatter is the Formatter used for converting log entries to bytes. This
 	// option is not used by Logger.
 	Formatter Formatter[E]
+
+	// HonorForcedLevel is whether the Handler receives events logged with a
+	// context created by WithForcedLevel even when they are below the level
+	// the Handler was added to a Logger at.
+	//
+	// When a Logger is copied with Copy, the original's value is kept unless
+	// this is true or was set with WithHonorForcedLevel, so WithHonorForcedLevel
+	// must be used to turn it off in a copy.
+	HonorForcedLevel bool
+
+	// honorForcedLevelSet is whether HonorForcedLevel was set with
+	// WithHonorForcedLevel.
+	honorForcedLevelSet bool
 }
 
 // WithFormatter returns a pointer to a copy of opts that has Formatter set to
@@ -27,6 +42,15 @@ func (opts HandlerOptions[E]) WithComponent(c string) *HandlerOptions[E] {
 	return &copy
 }
 
+// WithHonorForcedLevel returns a pointer to a copy of opts that has
+// HonorForcedLevel set to the given value.
+func (opts HandlerOptions[E]) WithHonorForcedLevel(honor bool) *HandlerOptions[E] {
+	copy := opts
+	copy.HonorForcedLevel = honor
+	copy.honorForcedLevelSet = true
+	return &copy
+}
+
 // Defaults returns an Options of the given type E with its properties set to
 // their default values.
 func Defaults[E any]() Options[E] {
@@ -41,7 +65,8 @@ type Options[E any] struct {
 	// Converter takes a value and converts it into an object of the type
 	// handled by the Logger. If this field is left nil, the default converter
 	// function is used. The default converter returns the zero-value of E,
-	// unless E is string - in this case, fmt.Sprintf("%v", v) is used.
+	// unless E is string - in this case, fmt.Sprintf("%v", v) is used, with v
+	// replaced by the result of its LogValue method if it is a LogValuer.
 	Converter func(v any) E
 
 	// Handlers is a slice of existing handlers to add to the Logger on
@@ -51,6 +76,106 @@ type Options[E any] struct {
 	// If LvAll is used as a map key, its slice of Handlers will receive all log
 	// events regardless of their level.
 	Handlers map[Level][]Handler[E]
+
+	// Extractors are called in order with the context of each event logged
+	// using one of the context-aware logging methods, such as InfoCtx. The
+	// Attrs they return are added to the event.
+	Extractors []ContextExtractor
+
+	// Processors are applied in order to every event logged through the
+	// Logger before it is dispatched to Handlers. Each may modify the event or
+	// drop it entirely. Loggers created with Copy inherit the Processors of the
+	// original unless different ones are given.
+	Processors []Processor[E]
+
+	// ErrorHandler is called with the error returned from dispatching an event
+	// to Handlers whenever it is non-nil and the logging method that was used
+	// has no way to return it, such as Info or Logf. If this field is left
+	// nil, errors are reported to stderr, at most once every ten seconds. To
+	// ignore errors entirely, set it to a function that does nothing.
+	//
+	// ErrorHandler should not log to the Logger it is set on, as the same
+	// failure is likely to occur again.
+	//
+	// Regardless of this field, the most recent error is always available from
+	// the Logger's LastError method, and the Try variants of the logging
+	// methods, such as TryInfo, return the error to the caller instead of
+	// passing it to ErrorHandler.
+	ErrorHandler func(err error)
+
+	// ParallelDispatch makes the Logger pass each event to all of its Handlers
+	// at once instead of one after another, so that a slow Handler does not
+	// delay the others. Each Handler still receives events one at a time and
+	// in the order they were logged. The logging call returns once every
+	// Handler has finished with the event or HandlerTimeout has elapsed.
+	//
+	// Each Handler is given events by a goroutine that belongs to the Logger,
+	// not by the goroutine that logged them. These goroutines exit once they
+	// have been idle for a while, and are stopped by Close. A Handler that is
+	// added more than once, or that is shared with a Logger created by Copy,
+	// still has a single goroutine, so it receives the events of all of them
+	// one at a time.
+	//
+	// When a Logger is copied with Copy, the original's value is kept unless
+	// this is true or was set with WithParallelDispatch, so
+	// WithParallelDispatch must be used to turn it off in a copy.
+	ParallelDispatch bool
+
+	// parallelDispatchSet is whether ParallelDispatch and HandlerTimeout were
+	// set with WithParallelDispatch.
+	parallelDispatchSet bool
+
+	// HandlerTimeout is how long a Logger with ParallelDispatch set waits for
+	// each Handler to finish with an event. Handlers that take longer are left
+	// to finish in the background, and an error wrapping ErrWriteTimeout is
+	// reported for each of them, through ErrorHandler if the logging method
+	// does not return errors. If not set, there is no timeout. It has no effect
+	// without ParallelDispatch; to put a timeout on a Handler in a Logger that
+	// dispatches sequentially, wrap it in a WriteTimeoutHandler.
+	HandlerTimeout time.Duration
+
+	// ComponentLevels sets a minimum level for events from particular
+	// components. Events below the minimum level for their component are
+	// dropped by the Logger before they reach any Handler, regardless of what
+	// level the Handlers were added at. The minimum for a component is taken
+	// from the longest key that is the component itself or one of its dotted
+	// prefixes, so with the keys "db" and "db.pool", the component
+	// "db.pool.conn" uses the level of "db.pool" and "db.query" uses that of
+	// "db". The key "" applies to all components that match no other key,
+	// including events with no component. Components matching no key at all
+	// are not filtered.
+	//
+	// This is checked against the fully-chained component of the event, before
+	// the Logger's Processors are applied. Events logged with a context created
+	// by WithForcedLevel at or above the forced level are not dropped, but are
+	// only sent to the Handlers with HonorForcedLevel set.
+	//
+	// Because this only removes events, Handlers must still be added at a low
+	// enough level to receive the events that a component is allowed to log.
+	// ParseComponentLevels can be used to create the map from a string.
+	ComponentLevels map[string]Level
+
+	// ExitFunc is called with the status code to exit the program by Fatal,
+	// its variants, and Exit, once logging has been shut down. If not set,
+	// os.Exit is used. This is mainly useful for tests of code that calls
+	// Fatal; if ExitFunc returns, so does the call that invoked it. When it is
+	// set, the Logger's Handlers are flushed before it is called but are not
+	// closed, so that the Logger can still be used if it returns.
+	ExitFunc func(code int)
+
+	// ExitTimeout is the most time that Exit spends running exit hooks and
+	// flushing and closing Handlers before exiting the program. If not set,
+	// DefaultExitTimeout is used.
+	ExitTimeout time.Duration
+
+	// StackTraceLevel is the minimum level of events that have a stack trace
+	// captured and stored in their Stack when they are logged. The trace
+	// starts at the call site of the logging method, so none of jellog's own
+	// functions are in it. If not set, no stack traces are captured; to have
+	// errors and fatal events carry a trace, set it to LvError. Capturing a
+	// trace walks the stack of the logging goroutine, so it adds to the cost
+	// of every event at or above this level.
+	StackTraceLevel *Level
 }
 
 // WithFormatter returns a copy of opts that has Formatter set to the given
@@ -69,6 +194,15 @@ func (opts Options[E]) WithComponent(c string) Options[E] {
 	return copy
 }
 
+// WithHonorForcedLevel returns a copy of opts that has HonorForcedLevel set to
+// the given value.
+func (opts Options[E]) WithHonorForcedLevel(honor bool) Options[E] {
+	copy := opts
+	copy.HonorForcedLevel = honor
+	copy.honorForcedLevelSet = true
+	return copy
+}
+
 // WithConverter returns a copy of opts that has Converter set to the given
 // value.
 func (opts Options[E]) WithConverter(c func(v any) E) Options[E] {
@@ -89,3 +223,64 @@ func (opts Options[E]) WithHandler(lv Level, hdl Handler[E]) Options[E] {
 	copy.Handlers[lv] = curHandlers
 	return copy
 }
+
+// WithExtractor returns a copy of opts that includes the given
+// ContextExtractor in its Extractors.
+func (opts Options[E]) WithExtractor(ext ContextExtractor) Options[E] {
+	copy := opts
+	copy.Extractors = append(append([]ContextExtractor{}, opts.Extractors...), ext)
+	return copy
+}
+
+// WithProcessor returns a copy of opts that has the given Processor added to
+// the end of its Processors.
+func (opts Options[E]) WithProcessor(p Processor[E]) Options[E] {
+	copy := opts
+	copy.Processors = append(append([]Processor[E]{}, opts.Processors...), p)
+	return copy
+}
+
+// WithErrorHandler returns a copy of opts that has ErrorHandler set to the
+// given value.
+func (opts Options[E]) WithErrorHandler(eh func(err error)) Options[E] {
+	copy := opts
+	copy.ErrorHandler = eh
+	return copy
+}
+
+// WithParallelDispatch returns a copy of opts that has ParallelDispatch set to
+// the given value and HandlerTimeout set to timeout.
+func (opts Options[E]) WithParallelDispatch(parallel bool, timeout time.Duration) Options[E] {
+	copy := opts
+	copy.ParallelDispatch = parallel
+	copy.HandlerTimeout = timeout
+	copy.parallelDispatchSet = true
+	return copy
+}
+
+// WithComponentLevel returns a copy of opts that has the minimum level of
+// component c set to lv in its ComponentLevels.
+func (opts Options[E]) WithComponentLevel(c string, lv Level) Options[E] {
+	copy := opts
+	copy.ComponentLevels = make(map[string]Level, len(opts.ComponentLevels)+1)
+	for k, v := range opts.ComponentLevels {
+		copy.ComponentLevels[k] = v
+	}
+	copy.ComponentLevels[c] = lv
+	return copy
+}
+
+// WithStackTraceLevel returns a copy of opts that has StackTraceLevel set to
+// the given value.
+func (opts Options[E]) WithStackTraceLevel(lv Level) Options[E] {
+	copy := opts
+	copy.StackTraceLevel = &lv
+	return copy
+}
+
+// WithExitFunc returns a copy of opts that has ExitFunc set to the given value.
+func (opts Options[E]) WithExitFunc(exit func(code int)) Options[E] {
+	copy := opts
+	copy.ExitFunc = exit
+	return copy
+}
@@ -0,0 +1,499 @@
+package jellog
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+type patternVerb int
+
+const (
+	patLiteral patternVerb = iota
+	patTime
+	patLevel
+	patLevelChar
+	patSeverity
+	patComponent
+	patMessage
+	patCaller
+	patFunction
+	patAttrs
+	patAttr
+	patStack
+	patError
+	patCauses
+)
+
+var patternVerbNames = map[string]patternVerb{
+	"time":      patTime,
+	"level":     patLevel,
+	"levelchar": patLevelChar,
+	"severity":  patSeverity,
+	"component": patComponent,
+	"message":   patMessage,
+	"caller":    patCaller,
+	"function":  patFunction,
+	"attrs":     patAttrs,
+	"attr":      patAttr,
+	"stack":     patStack,
+	"error":     patError,
+	"causes":    patCauses,
+}
+
+// named layouts that may be given as the argument to a time directive in place
+// of a Go time layout.
+var patternTimeLayouts = map[string]string{
+	"rfc3339":     time.RFC3339,
+	"rfc3339nano": time.RFC3339Nano,
+	"iso8601":     "2006-01-02T15:04:05.000Z07:00",
+	"kitchen":     time.Kitchen,
+	"stamp":       time.Stamp,
+}
+
+type patternPart struct {
+	verb  patternVerb
+	lit   string
+	arg   string
+	width int
+	left  bool
+	max   int
+}
+
+// PatternFormat is a Formatter[string] that lays out each log entry according
+// to a pattern string, similar to the layouts used by python's logging module
+// or log4j. The pattern is compiled once by NewPatternFormat, so formatting an
+// event does not require re-parsing it. A newline is automatically added to
+// the end of each entry if it doesn't already end with one.
+//
+// A pattern is made of literal text and directives. Each directive has the form
+// %{name} or %{name:arg}, and may have a width and maximum length between the %
+// and the {, as in %-10.10{component}:
+//
+//   - A width pads the value with spaces to at least that many characters. The
+//     value is right-justified unless the width is preceded by a '-', in which
+//     case it is left-justified.
+//   - A maximum length, given as a '.' followed by a number, truncates the
+//     value to at most that many characters, keeping the start of the value.
+//
+// The following directives are supported:
+//
+//   - %{time} - The time of the event. By default, this uses the same layout
+//     as LineFormat. The arg may be a Go time layout such as
+//     "2006-01-02T15:04:05Z07:00", or one of the named layouts "rfc3339",
+//     "rfc3339nano", "iso8601" (RFC 3339 with milliseconds), "kitchen", or
+//     "stamp".
+//   - %{level} - The name of the event's level, such as "INFO".
+//   - %{levelchar} - The first letter of the name of the event's level, such
+//     as "I".
+//   - %{severity} - The severity of the event's level as a number.
+//   - %{component} - The component of the event. If the event has no
+//     component, nothing is output. The arg may be a fmt-style format string
+//     that the component is inserted into with %s; it must contain %s exactly
+//     once, and no other verbs except %%. It is only used when there is a
+//     component, which allows decorations such as %{component:(%s) } to be
+//     omitted entirely for events without one. Control characters in the
+//     component are escaped.
+//   - %{message} - The logged message, with any trailing newline removed. It
+//     is written out according to the Messages field.
+//   - %{caller} - The file name and line number of the call site that logged
+//     the event, as in "server.go:32". Use %{caller:long} to show the full
+//     path of the file.
+//   - %{function} - The fully-qualified name of the function that logged the
+//     event.
+//   - %{attrs} - All Attrs of the event, as space-separated key=value pairs.
+//     A value is quoted and escaped as a Go string if it is empty, if it
+//     contains spaces, quotes, or '=', or if it has any characters that are
+//     not printable. Control characters in keys are escaped.
+//   - %{attr:key} - The value of the Attr with the given key, quoted in the
+//     same way. If the event has no such Attr, nothing is output.
+//   - %{stack} - The stack trace of the event, if it has one, on indented lines
+//     in the same layout that LineFormat uses. It begins with a newline so
+//     that it can be placed directly after %{message}. If the event has no
+//     stack trace, nothing is output.
+//   - %{error} - The message of the error that the event was logged for by
+//     one of the error logging methods, such as Logger.Err. It is written out
+//     according to the Messages field, treated as a message created by a
+//     Converter. If the event was not logged for an error, nothing is output.
+//   - %{causes} - The errors wrapped by the error that the event was logged
+//     for, on indented lines in the same layout that LineFormat uses. Like
+//     %{stack}, it begins with a newline, and nothing is output if there are
+//     none.
+//
+// A literal '%' is given by "%%".
+//
+// The zero-value of PatternFormat formats events the same way as LineFormat
+// with default options, but without color.
+type PatternFormat struct {
+	// UTC is whether to give the timestamp in each log entry in UTC time as
+	// opposed to the local timezone. It has no effect if Location is set.
+	UTC bool
+
+	// Location is the time zone that the timestamp of each log entry is given
+	// in. If not set, the local time zone is used, unless UTC is set.
+	Location *time.Location
+
+	// Messages is how the logged message is written out for %{message}. The
+	// default, MessageAuto, writes messages given directly to the Logger as-is
+	// and uses MessageIndent for messages created by the Logger's Converter.
+	Messages MessageMode
+
+	// ContinuationMarker is the text placed at the start of each continuation
+	// line of a message when MessageIndent is in use. If not set,
+	// DefaultContinuationMarker is used.
+	ContinuationMarker string
+
+	pattern string
+	parts   []patternPart
+}
+
+// NewPatternFormat compiles pattern into a PatternFormat. See PatternFormat for
+// the syntax of pattern. An error is returned if pattern contains an invalid
+// directive.
+func NewPatternFormat(pattern string) (PatternFormat, error) {
+	parts, err := compilePattern(pattern)
+	if err != nil {
+		return PatternFormat{}, err
+	}
+	return PatternFormat{pattern: pattern, parts: parts}, nil
+}
+
+// MustPatternFormat is the same as NewPatternFormat but panics if an error
+// would occur.
+func MustPatternFormat(pattern string) PatternFormat {
+	pf, err := NewPatternFormat(pattern)
+	if err != nil {
+		panic(err)
+	}
+	return pf
+}
+
+// DefaultPattern is the pattern that gives the same output as LineFormat with
+// default options.
+const DefaultPattern = "%{time} %-5{level} %{component:(%s) }%{message}%{causes}%{stack}"
+
+var defaultPatternParts = func() []patternPart {
+	parts, err := compilePattern(DefaultPattern)
+	if err != nil {
+		panic(err)
+	}
+	return parts
+}()
+
+// Pattern returns the pattern that pf was compiled from.
+func (pf PatternFormat) Pattern() string {
+	if pf.parts == nil {
+		return DefaultPattern
+	}
+	return pf.pattern
+}
+
+// Format formats a log event according to pf's pattern.
+func (pf PatternFormat) Format(evt Event[string]) []byte {
+	parts := pf.parts
+	if parts == nil {
+		parts = defaultPatternParts
+	}
+
+	buf := make([]byte, 0, len(evt.Message)+64)
+	var scratch []byte
+	for _, p := range parts {
+		if p.verb == patLiteral {
+			buf = append(buf, p.lit...)
+			continue
+		}
+
+		if p.width == 0 && p.max == 0 {
+			buf = pf.appendPart(buf, p, evt)
+			continue
+		}
+
+		scratch = pf.appendPart(scratch[:0], p, evt)
+		buf = appendPadded(buf, scratch, p.width, p.left, p.max)
+	}
+
+	if len(buf) == 0 || buf[len(buf)-1] != '\n' {
+		buf = append(buf, '\n')
+	}
+	return buf
+}
+
+// Break returns the newline character '\n'.
+func (pf PatternFormat) Break() []byte {
+	return []byte{'\n'}
+}
+
+func (pf PatternFormat) appendPart(buf []byte, p patternPart, evt Event[string]) []byte {
+	switch p.verb {
+	case patTime:
+		t := evt.Time
+		if pf.Location != nil {
+			t = t.In(pf.Location)
+		} else if pf.UTC {
+			t = t.UTC()
+		}
+		return appendTime(buf, t, p.arg, PrecisionSeconds)
+	case patLevel:
+		return append(buf, evt.Level.Name...)
+	case patLevelChar:
+		ch, _ := utf8.DecodeRuneInString(evt.Level.Name)
+		if ch == utf8.RuneError {
+			return buf
+		}
+		return utf8.AppendRune(buf, unicode.ToUpper(ch))
+	case patSeverity:
+		return strconv.AppendInt(buf, int64(evt.Level.Severity), 10)
+	case patComponent:
+		if evt.Component == "" {
+			return buf
+		}
+		// components can be chained from outside data, so they are escaped
+		// like the attrs to keep them from breaking up the entry
+		comp := evt.Component
+		if needsEscape(comp, false) {
+			comp = string(appendEscaped(nil, comp, ""))
+		}
+		if p.arg == "" {
+			return append(buf, comp...)
+		}
+		return fmt.Appendf(buf, p.arg, comp)
+	case patMessage:
+		msg := formatMessage(evt.Message, pf.Messages, evt.Converted, pf.ContinuationMarker)
+		return append(buf, strings.TrimSuffix(msg, "\n")...)
+	case patCaller:
+		file, line, _, ok := evt.Caller()
+		if !ok {
+			return append(buf, "???"...)
+		}
+		if p.arg != "long" {
+			file = filepath.Base(file)
+		}
+		buf = append(buf, file...)
+		buf = append(buf, ':')
+		return strconv.AppendInt(buf, int64(line), 10)
+	case patFunction:
+		_, _, fn, ok := evt.Caller()
+		if !ok {
+			return append(buf, "???"...)
+		}
+		return append(buf, fn...)
+	case patAttrs:
+		for i, a := range evt.Attrs {
+			if i > 0 {
+				buf = append(buf, ' ')
+			}
+			buf = appendEscaped(buf, a.Key, "")
+			buf = append(buf, '=')
+			buf = appendAttrValue(buf, a.Value)
+		}
+		return buf
+	case patAttr:
+		for _, a := range evt.Attrs {
+			if a.Key == p.arg {
+				return appendAttrValue(buf, a.Value)
+			}
+		}
+		return buf
+	case patStack:
+		if len(evt.Stack) == 0 {
+			return buf
+		}
+		buf = append(buf, '\n')
+		buf = appendStack(buf, evt.Stack)
+		return buf[:len(buf)-1]
+	case patError:
+		if evt.Err == nil {
+			return buf
+		}
+		// error messages are not trusted any more than converted messages
+		msg := formatMessage(evt.Err.Error(), pf.Messages, true, pf.ContinuationMarker)
+		return append(buf, strings.TrimSuffix(msg, "\n")...)
+	case patCauses:
+		start := len(buf)
+		buf = append(buf, '\n')
+		buf = appendCauses(buf, evt.Causes)
+		if len(buf) == start+1 {
+			return buf[:start]
+		}
+		return buf[:len(buf)-1]
+	}
+	return buf
+}
+
+// appendAttrValue appends the text form of an Attr value to buf, quoting it if
+// it would otherwise be ambiguous in a list of key=value pairs or if it has any
+// characters that are not printable, which the quoting escapes.
+func appendAttrValue(buf []byte, v any) []byte {
+	s := fmt.Sprint(resolveLogValue(v))
+	if s == "" || strings.ContainsAny(s, " \t\r\n\"=") || !printableAttrValue(s) {
+		return strconv.AppendQuote(buf, s)
+	}
+	return append(buf, s...)
+}
+
+// printableAttrValue returns whether s can be written out as an Attr value
+// without quoting, that is, whether it is valid UTF-8 made up only of
+// printable characters.
+func printableAttrValue(s string) bool {
+	if !strconv.CanBackquote(s) {
+		return false
+	}
+	for _, ch := range s {
+		if !strconv.IsPrint(ch) {
+			return false
+		}
+	}
+	return true
+}
+
+// appendPadded appends val to buf, truncated to at most max characters if max
+// is greater than 0, and then padded with spaces to at least width characters.
+func appendPadded(buf []byte, val []byte, width int, left bool, max int) []byte {
+	n := utf8.RuneCount(val)
+	if max > 0 && n > max {
+		cut := 0
+		for i := 0; i < max; i++ {
+			_, size := utf8.DecodeRune(val[cut:])
+			cut += size
+		}
+		val = val[:cut]
+		n = max
+	}
+
+	if !left {
+		for i := n; i < width; i++ {
+			buf = append(buf, ' ')
+		}
+	}
+	buf = append(buf, val...)
+	if left {
+		for i := n; i < width; i++ {
+			buf = append(buf, ' ')
+		}
+	}
+	return buf
+}
+
+func compilePattern(pattern string) ([]patternPart, error) {
+	var parts []patternPart
+	var lit strings.Builder
+
+	flushLit := func() {
+		if lit.Len() > 0 {
+			parts = append(parts, patternPart{verb: patLiteral, lit: lit.String()})
+			lit.Reset()
+		}
+	}
+
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] != '%' {
+			lit.WriteByte(pattern[i])
+			continue
+		}
+
+		start := i
+		i++
+		if i < len(pattern) && pattern[i] == '%' {
+			lit.WriteByte('%')
+			continue
+		}
+
+		var p patternPart
+		if i < len(pattern) && pattern[i] == '-' {
+			p.left = true
+			i++
+		}
+		p.width, i = scanPatternInt(pattern, i)
+		if i < len(pattern) && pattern[i] == '.' {
+			i++
+			numStart := i
+			p.max, i = scanPatternInt(pattern, i)
+			if i == numStart {
+				return nil, fmt.Errorf("pattern position %d: '.' must be followed by a maximum length", start)
+			}
+		}
+
+		if i >= len(pattern) || pattern[i] != '{' {
+			return nil, fmt.Errorf("pattern position %d: '%%' must be followed by a directive such as %%{message} or by another '%%'", start)
+		}
+		end := strings.IndexByte(pattern[i:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("pattern position %d: directive is missing closing '}'", start)
+		}
+		spec := pattern[i+1 : i+end]
+		i += end
+
+		name, arg, _ := strings.Cut(spec, ":")
+		verb, ok := patternVerbNames[name]
+		if !ok {
+			return nil, fmt.Errorf("pattern position %d: unknown directive %q", start, name)
+		}
+		p.verb = verb
+		p.arg = arg
+
+		switch verb {
+		case patTime:
+			if layout, ok := patternTimeLayouts[strings.ToLower(arg)]; ok {
+				p.arg = layout
+			}
+		case patAttr:
+			if arg == "" {
+				return nil, fmt.Errorf("pattern position %d: %%{attr} requires a key, as in %%{attr:key}", start)
+			}
+		case patCaller:
+			if arg != "" && arg != "long" {
+				return nil, fmt.Errorf("pattern position %d: unknown caller argument %q", start, arg)
+			}
+		case patComponent:
+			if arg != "" && !validComponentFormat(arg) {
+				return nil, fmt.Errorf("pattern position %d: component format %q must contain %%s exactly once and no other verbs except %%%%", start, arg)
+			}
+		}
+
+		flushLit()
+		parts = append(parts, p)
+	}
+	flushLit()
+
+	if parts == nil {
+		parts = []patternPart{}
+	}
+	return parts, nil
+}
+
+// validComponentFormat returns whether f is a format string that a component
+// can be inserted into with fmt, which is one with a single %s and no other
+// verbs except %%.
+func validComponentFormat(f string) bool {
+	verbs := 0
+	for i := 0; i < len(f); i++ {
+		if f[i] != '%' {
+			continue
+		}
+		i++
+		if i >= len(f) {
+			return false
+		}
+		switch f[i] {
+		case '%':
+		case 's':
+			verbs++
+		default:
+			return false
+		}
+	}
+	return verbs == 1
+}
+
+func scanPatternInt(s string, i int) (int, int) {
+	n := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		n = n*10 + int(s[i]-'0')
+		i++
+	}
+	return n, i
+}
@@ -0,0 +1,350 @@
+package jellog_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dekarrin/jellog"
+	"github.com/dekarrin/jellog/jellogtest"
+)
+
+// patternEvent returns an event with every field that a pattern directive can
+// output set.
+func patternEvent() jellog.Event[string] {
+	return jellog.Event[string]{
+		Time:      time.Date(2026, 3, 4, 5, 6, 7, 890123456, time.UTC),
+		Level:     jellog.LvWarn,
+		Component: "db.pool",
+		Message:   "disk full\n",
+		Attrs:     []jellog.Attr{{Key: "user", Value: "bob"}, {Key: "n", Value: 3}},
+		Stack:     []jellog.Frame{{Function: "main.run", File: "/src/main.go", Line: 42}},
+		Err:       errors.New("write failed"),
+		Causes: []jellog.Cause{
+			{Message: "write failed", Type: "*errors.errorString"},
+			{Message: "no space", Type: "*errors.errorString", Depth: 1},
+		},
+	}
+}
+
+func Test_PatternFormat_Format(t *testing.T) {
+	testCases := []struct {
+		name    string
+		pattern string
+		evt     *jellog.Event[string]
+		expect  string
+	}{
+		{name: "literal only", pattern: "hello", expect: "hello\n"},
+		{name: "empty pattern", pattern: "", expect: "\n"},
+		{name: "trailing newline is not doubled", pattern: "hello\n", expect: "hello\n"},
+		{name: "percent", pattern: "100%% done %%{message}", expect: "100% done %{message}\n"},
+		{name: "time", pattern: "%{time}", expect: "2026/03/04 05:06:07\n"},
+		{name: "level", pattern: "%{level}", expect: "WARN\n"},
+		{name: "levelchar", pattern: "%{levelchar}", expect: "W\n"},
+		{name: "severity", pattern: "%{severity}", expect: fmt.Sprintf("%d\n", jellog.LvWarn.Severity)},
+		{name: "component", pattern: "%{component}", expect: "db.pool\n"},
+		{name: "component with format", pattern: "%{component:[%s] }x", expect: "[db.pool] x\n"},
+		{name: "component format with percent", pattern: "%{component:%s 100%%}", expect: "db.pool 100%\n"},
+		{name: "no component omits format", pattern: "%{component:[%s] }x", evt: &jellog.Event[string]{}, expect: "x\n"},
+		{name: "message", pattern: "<%{message}>", expect: "<disk full>\n"},
+		{name: "attrs", pattern: "%{attrs}", expect: "user=bob n=3\n"},
+		{name: "attr", pattern: "%{attr:n}", expect: "3\n"},
+		{name: "missing attr", pattern: "[%{attr:none}]", expect: "[]\n"},
+		{name: "stack", pattern: "%{message}%{stack}", expect: "disk full\n\tmain.run\n\t\t/src/main.go:42\n"},
+		{name: "no stack", pattern: "%{message}%{stack}", evt: &jellog.Event[string]{Message: "m"}, expect: "m\n"},
+		{name: "error", pattern: "%{error}", expect: "write failed\n"},
+		{name: "no error", pattern: "[%{error}]", evt: &jellog.Event[string]{}, expect: "[]\n"},
+		{name: "causes", pattern: "%{message}%{causes}", expect: "disk full\n\tcaused by: no space [*errors.errorString]\n"},
+		{name: "no causes", pattern: "%{message}%{causes}", evt: &jellog.Event[string]{Message: "m"}, expect: "m\n"},
+		{name: "no caller", pattern: "%{caller} %{function}", expect: "??? ???\n"},
+		{
+			name:    "default pattern",
+			pattern: jellog.DefaultPattern,
+			expect:  "2026/03/04 05:06:07 WARN  (db.pool) disk full\n\tcaused by: no space [*errors.errorString]\n\tmain.run\n\t\t/src/main.go:42\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			evt := patternEvent()
+			if tc.evt != nil {
+				evt = *tc.evt
+			}
+			pf := jellog.MustPatternFormat(tc.pattern)
+			pf.UTC = true
+
+			actual := string(pf.Format(evt))
+
+			if actual != tc.expect {
+				t.Errorf("expected %q, got %q", tc.expect, actual)
+			}
+		})
+	}
+}
+
+func Test_PatternFormat_Format_escaping(t *testing.T) {
+	testCases := []struct {
+		name    string
+		pattern string
+		evt     jellog.Event[string]
+		expect  string
+	}{
+		{
+			name:    "plain value is not quoted",
+			pattern: "%{attrs}",
+			evt:     jellog.Event[string]{Attrs: []jellog.Attr{{Key: "user", Value: "bob"}}},
+			expect:  "user=bob\n",
+		},
+		{
+			name:    "printable unicode is not quoted",
+			pattern: "%{attrs}",
+			evt:     jellog.Event[string]{Attrs: []jellog.Attr{{Key: "city", Value: "Zürich"}}},
+			expect:  "city=Zürich\n",
+		},
+		{
+			name:    "empty value",
+			pattern: "%{attrs}",
+			evt:     jellog.Event[string]{Attrs: []jellog.Attr{{Key: "user", Value: ""}}},
+			expect:  "user=\"\"\n",
+		},
+		{
+			name:    "value with space and equals",
+			pattern: "%{attrs}",
+			evt:     jellog.Event[string]{Attrs: []jellog.Attr{{Key: "q", Value: "a=1 b=2"}}},
+			expect:  "q=\"a=1 b=2\"\n",
+		},
+		{
+			name:    "value with escape sequence",
+			pattern: "%{attrs}",
+			evt:     jellog.Event[string]{Attrs: []jellog.Attr{{Key: "user", Value: "\x1b[31mroot"}}},
+			expect:  "user=\"\\x1b[31mroot\"\n",
+		},
+		{
+			name:    "value with other control character",
+			pattern: "%{attrs}",
+			evt:     jellog.Event[string]{Attrs: []jellog.Attr{{Key: "user", Value: "a\x7fb\x00"}}},
+			expect:  "user=\"a\\x7fb\\x00\"\n",
+		},
+		{
+			name:    "value with line separator",
+			pattern: "%{attrs}",
+			evt:     jellog.Event[string]{Attrs: []jellog.Attr{{Key: "user", Value: "a\u2028b"}}},
+			expect:  "user=\"a\\u2028b\"\n",
+		},
+		{
+			name:    "value with invalid UTF-8",
+			pattern: "%{attrs}",
+			evt:     jellog.Event[string]{Attrs: []jellog.Attr{{Key: "user", Value: "a\xffb"}}},
+			expect:  "user=\"a\\xffb\"\n",
+		},
+		{
+			name:    "value with backquote",
+			pattern: "%{attrs}",
+			evt:     jellog.Event[string]{Attrs: []jellog.Attr{{Key: "cmd", Value: "`id`"}}},
+			expect:  "cmd=\"`id`\"\n",
+		},
+		{
+			name:    "key with control character",
+			pattern: "%{attrs}",
+			evt:     jellog.Event[string]{Attrs: []jellog.Attr{{Key: "a\rb", Value: "1"}}},
+			expect:  "a\\rb=1\n",
+		},
+		{
+			name:    "single attr is quoted the same",
+			pattern: "%{attr:user}",
+			evt:     jellog.Event[string]{Attrs: []jellog.Attr{{Key: "user", Value: "\x1b[31mroot"}}},
+			expect:  "\"\\x1b[31mroot\"\n",
+		},
+		{
+			name:    "plain component",
+			pattern: "%{component}",
+			evt:     jellog.Event[string]{Component: "db.pool"},
+			expect:  "db.pool\n",
+		},
+		{
+			name:    "component with control characters",
+			pattern: "%{component}",
+			evt:     jellog.Event[string]{Component: "db\n2026/01/01 INFO\x1b[0m"},
+			expect:  "db\\n2026/01/01 INFO\\x1b[0m\n",
+		},
+		{
+			name:    "component with format",
+			pattern: "%{component:(%s) }",
+			evt:     jellog.Event[string]{Component: "db\rx"},
+			expect:  "(db\\rx) \n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pf := jellog.MustPatternFormat(tc.pattern)
+
+			actual := string(pf.Format(tc.evt))
+
+			if actual != tc.expect {
+				t.Errorf("expected %q, got %q", tc.expect, actual)
+			}
+		})
+	}
+}
+
+func Test_PatternFormat_Format_caller(t *testing.T) {
+	rec := jellogtest.NewRecorder[string](nil)
+	lg := jellog.New(jellog.Defaults[string]())
+	lg.AddHandler(jellog.LvTrace, rec)
+
+	lg.Info("hello")
+	evt := rec.Events()[0]
+	file, line, fn, _ := evt.Caller()
+
+	testCases := []struct {
+		name    string
+		pattern string
+		expect  string
+	}{
+		{name: "caller", pattern: "%{caller}", expect: fmt.Sprintf("pattern_test.go:%d\n", line)},
+		{name: "long caller", pattern: "%{caller:long}", expect: fmt.Sprintf("%s:%d\n", file, line)},
+		{name: "function", pattern: "%{function}", expect: fn + "\n"},
+	}
+
+	if !strings.HasSuffix(fn, ".Test_PatternFormat_Format_caller") {
+		t.Fatalf("expected event to be logged from test function, got %q", fn)
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := string(jellog.MustPatternFormat(tc.pattern).Format(evt))
+
+			if actual != tc.expect {
+				t.Errorf("expected %q, got %q", tc.expect, actual)
+			}
+		})
+	}
+}
+
+func Test_PatternFormat_Format_width(t *testing.T) {
+	testCases := []struct {
+		name    string
+		pattern string
+		expect  string
+	}{
+		{name: "width pads on the left", pattern: "[%8{level}]", expect: "[    WARN]"},
+		{name: "minus pads on the right", pattern: "[%-8{level}]", expect: "[WARN    ]"},
+		{name: "width shorter than value", pattern: "[%2{level}]", expect: "[WARN]"},
+		{name: "max truncates", pattern: "[%.2{component}]", expect: "[db]"},
+		{name: "max longer than value", pattern: "[%.20{component}]", expect: "[db.pool]"},
+		{name: "width and max", pattern: "[%6.4{component}]", expect: "[  db.p]"},
+		{name: "minus width and max", pattern: "[%-6.4{component}]", expect: "[db.p  ]"},
+		{name: "max counts characters not bytes", pattern: "[%.3{attr:city}]", expect: "[Zür]"},
+		{name: "width counts characters not bytes", pattern: "[%7{attr:city}]", expect: "[ Zürich]"},
+		{name: "width on empty value", pattern: "[%3{attr:none}]", expect: "[   ]"},
+	}
+
+	evt := patternEvent()
+	evt.Attrs = append(evt.Attrs, jellog.Attr{Key: "city", Value: "Zürich"})
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := string(jellog.MustPatternFormat(tc.pattern).Format(evt))
+
+			if actual != tc.expect+"\n" {
+				t.Errorf("expected %q, got %q", tc.expect+"\n", actual)
+			}
+		})
+	}
+}
+
+func Test_PatternFormat_Format_time(t *testing.T) {
+	est := time.FixedZone("EST", -5*60*60)
+
+	testCases := []struct {
+		name     string
+		pattern  string
+		utc      bool
+		location *time.Location
+		expect   string
+	}{
+		{name: "default layout", pattern: "%{time}", utc: true, expect: "2026/03/04 05:06:07"},
+		{name: "rfc3339", pattern: "%{time:rfc3339}", utc: true, expect: "2026-03-04T05:06:07Z"},
+		{name: "rfc3339nano", pattern: "%{time:rfc3339nano}", utc: true, expect: "2026-03-04T05:06:07.890123456Z"},
+		{name: "iso8601", pattern: "%{time:iso8601}", utc: true, expect: "2026-03-04T05:06:07.890Z"},
+		{name: "kitchen", pattern: "%{time:kitchen}", utc: true, expect: "5:06AM"},
+		{name: "stamp", pattern: "%{time:stamp}", utc: true, expect: "Mar  4 05:06:07"},
+		{name: "named layouts ignore case", pattern: "%{time:RFC3339}", utc: true, expect: "2026-03-04T05:06:07Z"},
+		{name: "Go layout", pattern: "%{time:15:04:05.000 Jan 2}", utc: true, expect: "05:06:07.890 Mar 4"},
+		{name: "location", pattern: "%{time:rfc3339}", location: est, expect: "2026-03-04T00:06:07-05:00"},
+		{name: "location wins over UTC", pattern: "%{time:rfc3339}", utc: true, location: est, expect: "2026-03-04T00:06:07-05:00"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pf := jellog.MustPatternFormat(tc.pattern)
+			pf.UTC = tc.utc
+			pf.Location = tc.location
+
+			actual := string(pf.Format(patternEvent()))
+
+			if actual != tc.expect+"\n" {
+				t.Errorf("expected %q, got %q", tc.expect+"\n", actual)
+			}
+		})
+	}
+}
+
+func Test_NewPatternFormat_errors(t *testing.T) {
+	testCases := []struct {
+		name    string
+		pattern string
+	}{
+		{name: "width without directive", pattern: "%5"},
+		{name: "percent at end", pattern: "abc %"},
+		{name: "percent before text", pattern: "%d"},
+		{name: "unterminated directive", pattern: "%{message"},
+		{name: "unterminated directive after width", pattern: "%-5{level"},
+		{name: "unknown directive", pattern: "%{bogus}"},
+		{name: "dot without max", pattern: "%.{message}"},
+		{name: "attr without key", pattern: "%{attr}"},
+		{name: "unknown caller argument", pattern: "%{caller:short}"},
+		{name: "component format with other verb", pattern: "%{component:[%d]}"},
+		{name: "component format without verb", pattern: "%{component:db}"},
+		{name: "component format with two verbs", pattern: "%{component:%s.%s}"},
+		{name: "component format ending in percent", pattern: "%{component:%s%}"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := jellog.NewPatternFormat(tc.pattern)
+
+			if err == nil {
+				t.Errorf("expected error for pattern %q", tc.pattern)
+			}
+		})
+	}
+}
+
+func Test_MustPatternFormat_panics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected MustPatternFormat to panic")
+		}
+	}()
+	jellog.MustPatternFormat("%{message")
+}
+
+func Test_PatternFormat_zeroValue(t *testing.T) {
+	var pf jellog.PatternFormat
+	pf.UTC = true
+
+	if pf.Pattern() != jellog.DefaultPattern {
+		t.Errorf("expected zero-value pattern %q, got %q", jellog.DefaultPattern, pf.Pattern())
+	}
+
+	compiled := jellog.MustPatternFormat(jellog.DefaultPattern)
+	compiled.UTC = true
+	expect := string(compiled.Format(patternEvent()))
+	if actual := string(pf.Format(patternEvent())); actual != expect {
+		t.Errorf("expected %q, got %q", expect, actual)
+	}
+}
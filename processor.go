@@ -0,0 +1,138 @@
+package jellog
+
+import (
+	"os"
+	"strings"
+)
+
+// Processor transforms a log event before it is dispatched to Handlers. It
+// returns the event to continue with, which may be modified, and whether to
+// keep it; if keep is false, the event is dropped and is not passed to any
+// later Processors or to any Handlers.
+//
+// Processors can be configured on a Logger with Options.Processors, or on an
+// individual Handler by wrapping it with NewProcessingHandler.
+type Processor[E any] func(evt Event[E]) (out Event[E], keep bool)
+
+// runProcessors applies procs to evt in order, stopping as soon as one of them
+// drops it.
+func runProcessors[E any](procs []Processor[E], evt Event[E]) (Event[E], bool) {
+	for _, p := range procs {
+		var keep bool
+		evt, keep = p(evt)
+		if !keep {
+			return evt, false
+		}
+	}
+	return evt, true
+}
+
+// ProcessingHandler is a Handler that wraps another Handler and applies a chain
+// of Processors to each event before passing it through. This allows events to
+// be modified or dropped for a single Handler without affecting the other
+// Handlers of a Logger. It should be created with NewProcessingHandler.
+type ProcessingHandler[E any] struct {
+	h     Handler[E]
+	procs []Processor[E]
+}
+
+// NewProcessingHandler creates a ProcessingHandler that applies procs in order
+// to each event and then passes it through to h.
+func NewProcessingHandler[E any](h Handler[E], procs ...Processor[E]) *ProcessingHandler[E] {
+	return &ProcessingHandler[E]{
+		h:     h,
+		procs: append([]Processor[E]{}, procs...),
+	}
+}
+
+// HandlerOptions returns the options of the Handler that ph wraps.
+func (ph *ProcessingHandler[E]) HandlerOptions() HandlerOptions[E] {
+	return ph.h.HandlerOptions()
+}
+
+// InsertBreak inserts a break in the Handler that ph wraps.
+func (ph *ProcessingHandler[E]) InsertBreak() error {
+	return ph.h.InsertBreak()
+}
+
+// Output applies ph's Processors to a log event and then passes it through to
+// the wrapped Handler. If a Processor drops the event, nil is returned.
+//
+// The calldepth argument is used for recovering the program counter. It should
+// be supplied with the number of levels into the jellog package that the caller
+// has reached, with the externally called function counting as 1.
+func (ph *ProcessingHandler[E]) Output(calldepth int, evt Event[E]) error {
+	if evt.PC == 0 {
+		evt.PC = callerPC(calldepth)
+	}
+
+	evt, keep := runProcessors(ph.procs, evt)
+	if !keep {
+		return nil
+	}
+	return ph.h.Output(calldepth+1, evt)
+}
+
+// AddAttrs returns a Processor that adds the given Attrs to every event.
+func AddAttrs[E any](attrs ...Attr) Processor[E] {
+	attrs = append([]Attr{}, attrs...)
+	return func(evt Event[E]) (Event[E], bool) {
+		// copy so that we never modify an Attrs slice shared with the caller
+		newAttrs := make([]Attr, 0, len(evt.Attrs)+len(attrs))
+		newAttrs = append(newAttrs, evt.Attrs...)
+		evt.Attrs = append(newAttrs, attrs...)
+		return evt, true
+	}
+}
+
+// AddHostname returns a Processor that adds the name of the host, as reported
+// by os.Hostname, to every event as an Attr with the key "hostname". The name
+// is looked up once, when AddHostname is called. If it cannot be found, the
+// value "unknown" is used.
+func AddHostname[E any]() Processor[E] {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return AddAttrs[E](Attr{Key: "hostname", Value: host})
+}
+
+// RenameComponent returns a Processor that renames the component from to to.
+// Components that have from as a dotted prefix are renamed too, so renaming
+// "db" to "database" changes "db.pool" to "database.pool" but leaves "dbx"
+// alone.
+func RenameComponent[E any](from, to string) Processor[E] {
+	return func(evt Event[E]) (Event[E], bool) {
+		if evt.Component == from {
+			evt.Component = to
+		} else if strings.HasPrefix(evt.Component, from+".") {
+			evt.Component = to + evt.Component[len(from):]
+		}
+		return evt, true
+	}
+}
+
+// SetLevelIf returns a Processor that changes the level of every event that
+// match returns true for to lv. This can be used to raise or lower the level of
+// known noisy messages.
+func SetLevelIf[E any](match func(evt Event[E]) bool, lv Level) Processor[E] {
+	return func(evt Event[E]) (Event[E], bool) {
+		if match(evt) {
+			evt.Level = lv
+		}
+		return evt, true
+	}
+}
+
+// DropIf returns a Processor that drops every event that match returns true
+// for.
+func DropIf[E any](match func(evt Event[E]) bool) Processor[E] {
+	return func(evt Event[E]) (Event[E], bool) {
+		return evt, !match(evt)
+	}
+}
+
+// WrappedHandlers returns the Handler that ph wraps.
+func (ph *ProcessingHandler[E]) WrappedHandlers() []Handler[E] {
+	return []Handler[E]{ph.h}
+}
@@ -0,0 +1,200 @@
+package jellog_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dekarrin/jellog"
+	"github.com/dekarrin/jellog/jellogtest"
+)
+
+func Test_Processors(t *testing.T) {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	isNoisy := func(evt jellog.Event[string]) bool { return strings.HasPrefix(evt.Message, "noisy") }
+
+	testCases := []struct {
+		name       string
+		proc       jellog.Processor[string]
+		evt        jellog.Event[string]
+		expect     jellog.Event[string]
+		expectKeep bool
+	}{
+		{
+			name:       "AddAttrs appends",
+			proc:       jellog.AddAttrs[string](jellog.Attr{Key: "b", Value: 2}, jellog.Attr{Key: "c", Value: 3}),
+			evt:        jellog.Event[string]{Attrs: []jellog.Attr{{Key: "a", Value: 1}}},
+			expect:     jellog.Event[string]{Attrs: []jellog.Attr{{Key: "a", Value: 1}, {Key: "b", Value: 2}, {Key: "c", Value: 3}}},
+			expectKeep: true,
+		},
+		{
+			name:       "AddHostname",
+			proc:       jellog.AddHostname[string](),
+			evt:        jellog.Event[string]{},
+			expect:     jellog.Event[string]{Attrs: []jellog.Attr{{Key: "hostname", Value: host}}},
+			expectKeep: true,
+		},
+		{
+			name:       "RenameComponent exact",
+			proc:       jellog.RenameComponent[string]("db", "database"),
+			evt:        jellog.Event[string]{Component: "db"},
+			expect:     jellog.Event[string]{Component: "database"},
+			expectKeep: true,
+		},
+		{
+			name:       "RenameComponent dotted prefix",
+			proc:       jellog.RenameComponent[string]("db", "database"),
+			evt:        jellog.Event[string]{Component: "db.pool"},
+			expect:     jellog.Event[string]{Component: "database.pool"},
+			expectKeep: true,
+		},
+		{
+			name:       "RenameComponent ignores other prefixes",
+			proc:       jellog.RenameComponent[string]("db", "database"),
+			evt:        jellog.Event[string]{Component: "dbx"},
+			expect:     jellog.Event[string]{Component: "dbx"},
+			expectKeep: true,
+		},
+		{
+			name:       "SetLevelIf matching",
+			proc:       jellog.SetLevelIf(isNoisy, jellog.LvDebug),
+			evt:        jellog.Event[string]{Level: jellog.LvWarn, Message: "noisy thing"},
+			expect:     jellog.Event[string]{Level: jellog.LvDebug, Message: "noisy thing"},
+			expectKeep: true,
+		},
+		{
+			name:       "SetLevelIf not matching",
+			proc:       jellog.SetLevelIf(isNoisy, jellog.LvDebug),
+			evt:        jellog.Event[string]{Level: jellog.LvWarn, Message: "important thing"},
+			expect:     jellog.Event[string]{Level: jellog.LvWarn, Message: "important thing"},
+			expectKeep: true,
+		},
+		{
+			name:       "DropIf matching",
+			proc:       jellog.DropIf(isNoisy),
+			evt:        jellog.Event[string]{Message: "noisy thing"},
+			expect:     jellog.Event[string]{Message: "noisy thing"},
+			expectKeep: false,
+		},
+		{
+			name:       "DropIf not matching",
+			proc:       jellog.DropIf(isNoisy),
+			evt:        jellog.Event[string]{Message: "important thing"},
+			expect:     jellog.Event[string]{Message: "important thing"},
+			expectKeep: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, keep := tc.proc(tc.evt)
+
+			if keep != tc.expectKeep {
+				t.Errorf("expected keep to be %v, got %v", tc.expectKeep, keep)
+			}
+			if !reflect.DeepEqual(actual, tc.expect) {
+				t.Errorf("expected %+v, got %+v", tc.expect, actual)
+			}
+		})
+	}
+}
+
+func Test_AddAttrs_doesNotModifyEvent(t *testing.T) {
+	attrs := make([]jellog.Attr, 1, 4)
+	attrs[0] = jellog.Attr{Key: "a", Value: 1}
+	evt := jellog.Event[string]{Attrs: attrs}
+	proc := jellog.AddAttrs[string](jellog.Attr{Key: "b", Value: 2})
+
+	proc(evt)
+
+	// the spare capacity of the original slice must not have been written to
+	if extended := attrs[:2]; extended[1].Key != "" {
+		t.Errorf("expected original Attrs to be unchanged, got %v", extended)
+	}
+}
+
+func Test_Logger_Processors(t *testing.T) {
+	var order []string
+	tag := func(name string) jellog.Processor[string] {
+		return func(evt jellog.Event[string]) (jellog.Event[string], bool) {
+			order = append(order, name)
+			evt.Message += " " + name
+			return evt, true
+		}
+	}
+	dropNoisy := jellog.DropIf(func(evt jellog.Event[string]) bool { return strings.HasPrefix(evt.Message, "noisy") })
+
+	rec := jellogtest.NewRecorder[string](nil)
+	lg := jellog.New(jellog.Defaults[string]().
+		WithProcessor(dropNoisy).
+		WithProcessor(tag("first")).
+		WithProcessor(tag("second")))
+	lg.AddHandler(jellog.LvTrace, rec)
+
+	lg.Info("noisy")
+	lg.Info("a")
+
+	// processors are applied in order, and none after one that drops the event
+	// are called
+	if expect := []string{"first", "second"}; !reflect.DeepEqual(order, expect) {
+		t.Errorf("expected processors to be called as %q, got %q", expect, order)
+	}
+	if actual := messages(rec.Events()); !reflect.DeepEqual(actual, []string{"a first second"}) {
+		t.Errorf("expected only the processed event, got %q", actual)
+	}
+	if file, _, _, _ := rec.Events()[0].Caller(); filepath.Base(file) != "processor_test.go" {
+		t.Errorf("expected caller in processor_test.go, got %q", file)
+	}
+
+	// Copy inherits processors unless given its own
+	rec.Reset()
+	lg.Copy(jellog.Options[string]{}).Info("b")
+	lg.Copy(jellog.Options[string]{}.WithProcessor(tag("own"))).Info("c")
+	lg.Copy(jellog.Options[string]{}.WithComponent("child")).Info("noisy")
+
+	if actual, expect := messages(rec.Events()), []string{"b first second", "c own"}; !reflect.DeepEqual(actual, expect) {
+		t.Errorf("expected events %q, got %q", expect, actual)
+	}
+}
+
+func Test_ProcessingHandler(t *testing.T) {
+	plain := jellogtest.NewRecorder[string](nil)
+	processed := jellogtest.NewRecorder[string](nil)
+	ph := jellog.NewProcessingHandler[string](processed,
+		jellog.DropIf(func(evt jellog.Event[string]) bool { return evt.Message == "drop me" }),
+		jellog.RenameComponent[string]("db", "database"),
+	)
+
+	lg := jellog.New(jellog.Defaults[string]().WithComponent("db"))
+	lg.AddHandler(jellog.LvTrace, plain)
+	lg.AddHandler(jellog.LvTrace, ph)
+
+	lg.Info("a")
+	if err := lg.TryInfo("drop me"); err != nil {
+		t.Errorf("expected no error for dropped event, got %v", err)
+	}
+
+	// only the wrapped Handler sees the effects of the Processors
+	if actual := messages(plain.Events()); !reflect.DeepEqual(actual, []string{"a", "drop me"}) {
+		t.Errorf("expected unwrapped Handler to get all events, got %q", actual)
+	}
+	if plain.Events()[0].Component != "db" {
+		t.Errorf("expected unwrapped Handler to get component %q, got %q", "db", plain.Events()[0].Component)
+	}
+
+	evts := processed.Events()
+	if actual := messages(evts); !reflect.DeepEqual(actual, []string{"a"}) {
+		t.Fatalf("expected wrapped Handler to get only kept events, got %q", actual)
+	}
+	if evts[0].Component != "database" {
+		t.Errorf("expected wrapped Handler to get component %q, got %q", "database", evts[0].Component)
+	}
+	if file, _, _, _ := evts[0].Caller(); filepath.Base(file) != "processor_test.go" {
+		t.Errorf("expected caller in processor_test.go, got %q", file)
+	}
+}
@@ -0,0 +1,105 @@
+package jellog
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// Recover logs a panic at severity level ERROR and stops it. It must be called
+// directly with defer, as in:
+//
+//	defer lg.Recover()
+//
+// If the function that deferred it is not panicking, Recover does nothing.
+// Otherwise, it logs the value that was passed to panic() along with the stack
+// of the goroutine at the time of the panic, and the deferring function
+// returns normally to its caller as though it had not panicked. The source
+// code location of the logged event is where the panic occurred. As the
+// message is made from an arbitrary value, the event is marked as Converted.
+func (lg Logger[E]) Recover() {
+	if r := recover(); r != nil {
+		lg.logPanic(LvError, r)
+	}
+}
+
+// RecoverAndPanic logs a panic at severity level FATAL and then continues it.
+// It must be called directly with defer, as in:
+//
+//	defer lg.RecoverAndPanic()
+//
+// If the function that deferred it is not panicking, RecoverAndPanic does
+// nothing. Otherwise, it logs the value that was passed to panic() along with
+// the stack of the goroutine at the time of the panic, flushes the Logger so
+// that the event is not lost if the panic crashes the program, and then panics
+// again with the same value. The event is marked as Converted, as it is for
+// Recover.
+func (lg Logger[E]) RecoverAndPanic() {
+	if r := recover(); r != nil {
+		lg.logPanic(LvFatal, r)
+
+		timeout := lg.opts.ExitTimeout
+		if timeout <= 0 {
+			timeout = DefaultExitTimeout
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		lg.HandleError(lg.Flush(ctx))
+		cancel()
+
+		panic(r)
+	}
+}
+
+// Go calls f in a new goroutine that is protected by Recover. If f panics, the
+// panic is logged at severity level ERROR and the goroutine exits instead of
+// crashing the program.
+func (lg Logger[E]) Go(f func()) {
+	go func() {
+		defer lg.Recover()
+		f()
+	}()
+}
+
+// logPanic logs the recovered panic value r at level lv. It must be called
+// directly from the deferred function that recovered r.
+func (lg Logger[E]) logPanic(lv Level, r any) {
+	pcs := panicPCs()
+
+	evt := lg.CreateEvent(lv, fmt.Sprintf("panic: %v", r))
+
+	// the panic value can be anything, including an error or data from outside
+	// the program, so Formatters must not trust the message made from it
+	evt.Converted = true
+	evt.Attrs = append(evt.Attrs, Attr{Key: "panic", Value: r})
+	if len(pcs) > 0 {
+		evt.PC = pcs[0]
+		evt.Stack = stackFrames(pcs)
+	}
+
+	lg.HandleError(lg.Output(3, evt))
+}
+
+// panicPCs returns the program counters of the stack that the current panic
+// was started from, beginning with the location of the panic itself, or nil if
+// it cannot be found. It must be called while a deferred function is running
+// because of a panic.
+func panicPCs() []uintptr {
+	pcs := make([]uintptr, maxStackDepth)
+	n := runtime.Callers(1, pcs)
+
+	inPanic := false
+	for i, pc := range pcs[:n] {
+		f, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+		if !inPanic {
+			inPanic = f.Function == "runtime.gopanic"
+			continue
+		}
+		// skip the rest of the runtime's panic machinery, such as the frames
+		// that turn a nil dereference into a panic
+		if !strings.HasPrefix(f.Function, "runtime.") {
+			return pcs[i:n]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package jellog_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/dekarrin/jellog"
+	"github.com/dekarrin/jellog/jellogtest"
+)
+
+func Test_Logger_Recover(t *testing.T) {
+	rec := jellogtest.NewRecorder[string](nil)
+	lg := jellog.New(jellog.Defaults[string]())
+	lg.AddHandler(jellog.LvTrace, rec)
+
+	func() {
+		defer lg.Recover()
+		panic("boom\n2026/01/01 00:00:00 INFO forged")
+	}()
+
+	evts := rec.Events()
+	if len(evts) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(evts))
+	}
+	evt := evts[0]
+
+	if evt.Level != jellog.LvError {
+		t.Errorf("expected level %v, got %v", jellog.LvError, evt.Level)
+	}
+	if expect := "panic: boom\n2026/01/01 00:00:00 INFO forged"; evt.Message != expect {
+		t.Errorf("expected message %q, got %q", expect, evt.Message)
+	}
+	if !evt.Converted {
+		t.Errorf("expected panic event to be marked Converted")
+	}
+	if len(evt.Stack) == 0 {
+		t.Errorf("expected panic event to have a stack")
+	}
+	if file, _, _, _ := evt.Caller(); filepath.Base(file) != "recover_test.go" {
+		t.Errorf("expected caller in recover_test.go, got %q", file)
+	}
+}
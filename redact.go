@@ -0,0 +1,285 @@
+package jellog
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// RedactedMask is the text that redacted data is replaced with.
+const RedactedMask = "[REDACTED]"
+
+// Redacted holds a value that must never appear in log output, such as a
+// password or an access token. Every way of turning a Redacted into text,
+// including String, all fmt verbs, MarshalJSON, MarshalText, and LogValue,
+// gives RedactedMask instead of the value. Use Value to get the actual value.
+//
+// The value is held behind a function rather than in a field so that even
+// reflection-based printing, such as fmt printing a struct that has a Redacted
+// in an unexported field, cannot reach it.
+//
+// The zero-value of Redacted holds the zero-value of T.
+type Redacted[T any] struct {
+	get func() T
+}
+
+// Redact wraps v in a Redacted so that it is masked wherever it is logged.
+func Redact[T any](v T) Redacted[T] {
+	return Redacted[T]{get: func() T { return v }}
+}
+
+// Value returns the value held by r.
+func (r Redacted[T]) Value() T {
+	if r.get == nil {
+		var zero T
+		return zero
+	}
+	return r.get()
+}
+
+// String returns RedactedMask.
+func (r Redacted[T]) String() string {
+	return RedactedMask
+}
+
+// GoString returns RedactedMask.
+func (r Redacted[T]) GoString() string {
+	return RedactedMask
+}
+
+// Format writes RedactedMask for every verb.
+func (r Redacted[T]) Format(f fmt.State, verb rune) {
+	f.Write([]byte(RedactedMask))
+}
+
+// LogValue returns RedactedMask.
+func (r Redacted[T]) LogValue() any {
+	return RedactedMask
+}
+
+// MarshalText returns RedactedMask.
+func (r Redacted[T]) MarshalText() ([]byte, error) {
+	return []byte(RedactedMask), nil
+}
+
+// MarshalJSON returns RedactedMask as a JSON string.
+func (r Redacted[T]) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + RedactedMask + `"`), nil
+}
+
+// RedactRule is a pattern of sensitive data to be scrubbed from log output by a
+// Redactor.
+type RedactRule struct {
+	// Name identifies the rule. It is not used by Redactor.
+	Name string
+
+	// Pattern matches the sensitive data.
+	Pattern *regexp.Regexp
+
+	// Validate, if set, is called with each match of Pattern, and only matches
+	// that it returns true for are redacted. This can be used to reduce false
+	// positives of loose patterns.
+	Validate func(match string) bool
+
+	// Replacement is what each match is replaced with. It may refer to
+	// submatches of Pattern in the same way as regexp.Regexp.Expand. If not
+	// set, RedactedMask is used.
+	Replacement string
+}
+
+var (
+	// RedactBearerTokens matches bearer tokens as given in HTTP Authorization
+	// headers. The word "Bearer" is kept and only the token is redacted.
+	RedactBearerTokens = RedactRule{
+		Name:        "bearer-token",
+		Pattern:     regexp.MustCompile(`(?i)\b(bearer)\s+[A-Za-z0-9\-._~+/]+=*`),
+		Replacement: "${1} " + RedactedMask,
+	}
+
+	// RedactAWSKeys matches AWS access key IDs, as well as secret access keys
+	// that are given as the value of something named like one.
+	RedactAWSKeys = RedactRule{
+		Name:        "aws-key",
+		Pattern:     regexp.MustCompile(`\b(?:A3T[A-Z0-9]|AKIA|ASIA|AGPA|AIDA|AROA|AIPA|ANPA|ANVA)[A-Z0-9]{16}\b|(?i)(aws_?secret_?access_?key["']?\s*[:=]\s*["']?)[A-Za-z0-9/+=]{40}`),
+		Replacement: "${1}" + RedactedMask,
+	}
+
+	// RedactEmails matches email addresses.
+	RedactEmails = RedactRule{
+		Name:    "email",
+		Pattern: regexp.MustCompile(`\b[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}\b`),
+	}
+
+	// RedactCardNumbers matches payment card numbers of 13 to 19 digits,
+	// optionally separated by spaces or dashes, that pass the Luhn check.
+	RedactCardNumbers = RedactRule{
+		Name:     "card-number",
+		Pattern:  regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`),
+		Validate: luhnValid,
+	}
+)
+
+// DefaultRedactRules returns the built-in set of RedactRules: bearer tokens,
+// AWS keys, email addresses, and payment card numbers.
+func DefaultRedactRules() []RedactRule {
+	return []RedactRule{RedactBearerTokens, RedactAWSKeys, RedactEmails, RedactCardNumbers}
+}
+
+// DefaultRedactKeys returns the built-in set of Attr keys whose values are
+// always redacted in their entirety.
+func DefaultRedactKeys() []string {
+	return []string{"password", "passwd", "secret", "token", "apikey", "authorization", "cookie", "credential"}
+}
+
+// Redactor scrubs sensitive data from log messages and Attr values. It applies
+// a set of RedactRules to text, and it masks the entire value of any Attr whose
+// key contains one of a set of keywords. Use RedactProcessor to apply a
+// Redactor to every event logged through a Logger or Handler.
+//
+// A Redactor is safe to use from multiple goroutines. It should be created with
+// NewRedactor.
+type Redactor struct {
+	rules []RedactRule
+	keys  []string
+}
+
+// NewRedactor creates a Redactor that applies the given rules to text and that
+// masks the values of Attrs whose keys contain any of the given keywords.
+// Keywords are matched without regard to case, '-', or '_', so "apikey" matches
+// the keys "API_KEY" and "x-api-key". Rules and keys are typically given as
+// DefaultRedactRules() and DefaultRedactKeys(), optionally with additions.
+func NewRedactor(rules []RedactRule, keys []string) *Redactor {
+	r := &Redactor{rules: append([]RedactRule{}, rules...)}
+	for _, k := range keys {
+		r.keys = append(r.keys, normalizeRedactKey(k))
+	}
+	return r
+}
+
+// Redact returns s with everything matched by the Redactor's rules replaced.
+func (r *Redactor) Redact(s string) string {
+	for _, rule := range r.rules {
+		repl := rule.Replacement
+		if repl == "" {
+			repl = RedactedMask
+		}
+
+		s = rule.Pattern.ReplaceAllStringFunc(s, func(match string) string {
+			if rule.Validate != nil && !rule.Validate(match) {
+				return match
+			}
+			sub := rule.Pattern.FindStringSubmatchIndex(match)
+			return string(rule.Pattern.ExpandString(nil, repl, match, sub))
+		})
+	}
+	return s
+}
+
+// RedactAttr returns a with its value redacted. If the key of a matches one of
+// the Redactor's keywords, the value is replaced with RedactedMask entirely.
+// Otherwise, the value is converted to text in the same way it would be when
+// written out, and if the Redactor's rules change that text, the value is
+// replaced with the redacted text.
+func (r *Redactor) RedactAttr(a Attr) Attr {
+	key := normalizeRedactKey(a.Key)
+	for _, k := range r.keys {
+		if strings.Contains(key, k) {
+			a.Value = RedactedMask
+			return a
+		}
+	}
+
+	if a.Value == nil {
+		return a
+	}
+	text := fmt.Sprint(resolveLogValue(a.Value))
+	if redacted := r.Redact(text); redacted != text {
+		a.Value = redacted
+	}
+	return a
+}
+
+// RedactProcessor returns a Processor that applies r to every event. The
+// values of the event's Attrs are redacted with RedactAttr, and if E is
+// string, the message is redacted with Redact.
+//
+// If the event was logged for an error, the Message and Detail of each of its
+// Causes are redacted with Redact, and its Err is replaced with an error whose
+// message is redacted. The replacement wraps the original, so errors.Is and
+// errors.As still find it and anything it wraps.
+func RedactProcessor[E any](r *Redactor) Processor[E] {
+	return func(evt Event[E]) (Event[E], bool) {
+		if msg, ok := any(evt.Message).(string); ok {
+			evt.Message = any(r.Redact(msg)).(E)
+		}
+
+		if evt.Err != nil {
+			evt.Err = redactedError{err: evt.Err, msg: r.Redact(evt.Err.Error())}
+		}
+		if len(evt.Causes) > 0 {
+			causes := make([]Cause, len(evt.Causes))
+			for i, c := range evt.Causes {
+				c.Message = r.Redact(c.Message)
+				c.Detail = r.Redact(c.Detail)
+				causes[i] = c
+			}
+			evt.Causes = causes
+		}
+
+		if len(evt.Attrs) > 0 {
+			attrs := make([]Attr, len(evt.Attrs))
+			for i := range evt.Attrs {
+				attrs[i] = r.RedactAttr(evt.Attrs[i])
+			}
+			evt.Attrs = attrs
+		}
+		return evt, true
+	}
+}
+
+// redactedError is an error with a redacted message that wraps the original
+// error it was made from.
+type redactedError struct {
+	err error
+	msg string
+}
+
+func (e redactedError) Error() string {
+	return e.msg
+}
+
+func (e redactedError) Unwrap() error {
+	return e.err
+}
+
+func normalizeRedactKey(k string) string {
+	k = strings.ToLower(k)
+	k = strings.ReplaceAll(k, "_", "")
+	k = strings.ReplaceAll(k, "-", "")
+	return k
+}
+
+// luhnValid returns whether the digits in s pass the Luhn checksum. Any
+// characters in s other than digits are ignored.
+func luhnValid(s string) bool {
+	sum := 0
+	digits := 0
+	double := false
+	for i := len(s) - 1; i >= 0; i-- {
+		ch := s[i]
+		if ch < '0' || ch > '9' {
+			continue
+		}
+		d := int(ch - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+		digits++
+	}
+	return digits >= 13 && digits <= 19 && sum%10 == 0
+}
@@ -0,0 +1,29 @@
+package jellog
+
+import "testing"
+
+func Test_luhnValid(t *testing.T) {
+	testCases := []struct {
+		name   string
+		input  string
+		expect bool
+	}{
+		{name: "valid 16 digits", input: "4111111111111111", expect: true},
+		{name: "invalid 16 digits", input: "4111111111111112", expect: false},
+		{name: "valid with separators", input: "4111-1111 1111-1111", expect: true},
+		{name: "valid 13 digits", input: "4222222222222", expect: true},
+		{name: "valid 19 digits", input: "6011000000000000001", expect: true},
+		{name: "valid checksum but 12 digits", input: "123456789015", expect: false},
+		{name: "valid checksum but 20 digits", input: "12345678901234567894", expect: false},
+		{name: "no digits", input: "----", expect: false},
+		{name: "empty", input: "", expect: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := luhnValid(tc.input); actual != tc.expect {
+				t.Errorf("expected %v, got %v", tc.expect, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,298 @@
+package jellog
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+)
+
+// RouteMode is how a RouterHandler chooses between routes when more than one
+// of them matches an event.
+type RouteMode int
+
+const (
+	// RouteFirst sends each event only to the Handler of the first route that
+	// matches it.
+	RouteFirst RouteMode = iota
+
+	// RouteAll sends each event to the Handlers of every route that matches
+	// it.
+	RouteAll
+)
+
+// Route is a component pattern and the Handler that events with a matching
+// component are sent to by a RouterHandler.
+//
+// Patterns are matched against the entire chained component of an event, such
+// as "db.pool", using the syntax of path.Match; '*' matches any sequence of
+// characters, including dots. As a special case to make routing by component
+// hierarchy simple, a pattern with no wildcards also matches any sub-component
+// of the component it names, and a pattern ending in ".*" also matches the
+// component before it. So both "db" and "db.*" match the components "db" and
+// "db.pool", but not "dbx". The pattern "" matches only events without a
+// component, and "*" matches every event.
+type Route[E any] struct {
+	Pattern string
+	Handler Handler[E]
+}
+
+// RouterOptions is used to control the behavior of a RouterHandler. It is
+// passed to NewRouterHandler as an optional argument.
+type RouterOptions[E any] struct {
+	// HandlerOptions is the generic Handler options. If Component is set, it
+	// is chained onto each event's component before it is matched against
+	// routes. Formatter is not used.
+	HandlerOptions[E]
+
+	// Mode is how an event that matches more than one route is routed. If not
+	// set, RouteFirst is used.
+	Mode RouteMode
+
+	// Routes is the initial list of routes, in the order they are checked.
+	Routes []Route[E]
+
+	// Default is the Handler that events matching none of the routes are sent
+	// to. If not set, such events are discarded.
+	Default Handler[E]
+}
+
+// RouterHandler is a Handler that sends each event to other Handlers based on
+// the event's component. This allows, for example, events from "db" and its
+// sub-components to go to one file, events from "http" to another, and
+// everything else to stderr, in addition to whatever routing by level is done
+// by the Logger. See Route for how patterns are matched.
+//
+// Routes can be added, removed, and changed at any time, including while
+// events are being logged.
+//
+// A RouterHandler is safe to use from multiple goroutines. It should be created
+// with NewRouterHandler.
+type RouterHandler[E any] struct {
+	opts HandlerOptions[E]
+
+	mtx    sync.RWMutex
+	mode   RouteMode
+	routes []Route[E]
+	def    Handler[E]
+}
+
+// NewRouterHandler creates a RouterHandler with the given initial routes and
+// mode. An error is returned if any of the routes has an invalid pattern.
+//
+// To use the default set of RouterOptions, pass nil for opts.
+func NewRouterHandler[E any](opts *RouterOptions[E]) (*RouterHandler[E], error) {
+	var o RouterOptions[E]
+	if opts != nil {
+		o = *opts
+	}
+
+	rh := &RouterHandler[E]{opts: o.HandlerOptions, mode: o.Mode, def: o.Default}
+	for _, r := range o.Routes {
+		if err := rh.AddRoute(r.Pattern, r.Handler); err != nil {
+			return nil, err
+		}
+	}
+	return rh, nil
+}
+
+// MustRouterHandler is the same as NewRouterHandler but panics if an error
+// would occur.
+func MustRouterHandler[E any](opts *RouterOptions[E]) *RouterHandler[E] {
+	rh, err := NewRouterHandler(opts)
+	if err != nil {
+		panic(err)
+	}
+	return rh
+}
+
+// AddRoute adds a route to the end of the list of routes. An error is returned
+// if pattern is invalid.
+func (rh *RouterHandler[E]) AddRoute(pattern string, h Handler[E]) error {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid route pattern %q: %w", pattern, err)
+	}
+
+	rh.mtx.Lock()
+	defer rh.mtx.Unlock()
+
+	rh.routes = append(rh.routes, Route[E]{Pattern: pattern, Handler: h})
+	return nil
+}
+
+// RemoveRoute removes all routes with the given pattern and returns whether
+// there were any.
+func (rh *RouterHandler[E]) RemoveRoute(pattern string) bool {
+	rh.mtx.Lock()
+	defer rh.mtx.Unlock()
+
+	var kept []Route[E]
+	for _, r := range rh.routes {
+		if r.Pattern != pattern {
+			kept = append(kept, r)
+		}
+	}
+	removed := len(kept) != len(rh.routes)
+	rh.routes = kept
+	return removed
+}
+
+// SetRoutes replaces all routes with the given ones. An error is returned, and
+// the routes are left unchanged, if any of them has an invalid pattern.
+func (rh *RouterHandler[E]) SetRoutes(routes []Route[E]) error {
+	for _, r := range routes {
+		if _, err := path.Match(r.Pattern, ""); err != nil {
+			return fmt.Errorf("invalid route pattern %q: %w", r.Pattern, err)
+		}
+	}
+
+	rh.mtx.Lock()
+	defer rh.mtx.Unlock()
+
+	rh.routes = append([]Route[E]{}, routes...)
+	return nil
+}
+
+// Routes returns the current routes in the order they are checked.
+func (rh *RouterHandler[E]) Routes() []Route[E] {
+	rh.mtx.RLock()
+	defer rh.mtx.RUnlock()
+
+	return append([]Route[E]{}, rh.routes...)
+}
+
+// SetDefault sets the Handler that events matching no route are sent to. If h
+// is nil, such events are discarded.
+func (rh *RouterHandler[E]) SetDefault(h Handler[E]) {
+	rh.mtx.Lock()
+	defer rh.mtx.Unlock()
+
+	rh.def = h
+}
+
+// SetMode sets how events that match more than one route are routed.
+func (rh *RouterHandler[E]) SetMode(mode RouteMode) {
+	rh.mtx.Lock()
+	defer rh.mtx.Unlock()
+
+	rh.mode = mode
+}
+
+// HandlerOptions returns the options that the RouterHandler is configured
+// with. Modifying the returned struct has no effect on rh.
+func (rh *RouterHandler[E]) HandlerOptions() HandlerOptions[E] {
+	return rh.opts
+}
+
+// InsertBreak inserts a break in every Handler that rh routes to, including
+// the default.
+func (rh *RouterHandler[E]) InsertBreak() error {
+	rh.mtx.RLock()
+	var targets []Handler[E]
+	for _, r := range rh.routes {
+		targets = appendUniqueHandler(targets, r.Handler)
+	}
+	if rh.def != nil {
+		targets = appendUniqueHandler(targets, rh.def)
+	}
+	rh.mtx.RUnlock()
+
+	var fullErr error
+	for _, h := range targets {
+		fullErr = joinHandlerErr(fullErr, h.InsertBreak())
+	}
+	return fullErr
+}
+
+// Output sends a log event to the Handlers of the routes that match its
+// component, or to the default Handler if none do.
+//
+// The calldepth argument is used for recovering the program counter. It should
+// be supplied with the number of levels into the jellog package that the caller
+// has reached, with the externally called function counting as 1.
+func (rh *RouterHandler[E]) Output(calldepth int, evt Event[E]) error {
+	// chain our component with the event's component if we have one
+	if rh.opts.Component != "" {
+		if evt.Component != "" {
+			evt.Component += "."
+		}
+		evt.Component += rh.opts.Component
+	}
+
+	if evt.PC == 0 {
+		evt.PC = callerPC(calldepth)
+	}
+
+	rh.mtx.RLock()
+	var targets []Handler[E]
+	for _, r := range rh.routes {
+		if matchComponent(r.Pattern, evt.Component) {
+			targets = appendUniqueHandler(targets, r.Handler)
+			if rh.mode == RouteFirst {
+				break
+			}
+		}
+	}
+	if len(targets) == 0 && rh.def != nil {
+		targets = append(targets, rh.def)
+	}
+	rh.mtx.RUnlock()
+
+	var fullErr error
+	for _, h := range targets {
+		fullErr = joinHandlerErr(fullErr, h.Output(calldepth+1, evt))
+	}
+	return fullErr
+}
+
+// matchComponent returns whether component matches pattern as described in
+// the documentation for Route.
+func matchComponent(pattern, component string) bool {
+	if ok, _ := path.Match(pattern, component); ok {
+		return true
+	}
+
+	prefix := strings.TrimSuffix(pattern, ".*")
+	if prefix == pattern && strings.ContainsAny(pattern, `*?[\`) {
+		// not a plain component name, so no sub-component matching
+		return false
+	}
+	if prefix == "" {
+		return false
+	}
+	if ok, _ := path.Match(prefix, component); ok {
+		return true
+	}
+	if prefix != pattern {
+		// "x.*" has already been checked against sub-components
+		return false
+	}
+	return strings.HasPrefix(component, prefix+".")
+}
+
+// appendUniqueHandler appends h to hs if it is not already in it, as determined
+// by sameHandler.
+func appendUniqueHandler[E any](hs []Handler[E], h Handler[E]) []Handler[E] {
+	for i := range hs {
+		if sameHandler(hs[i], h) {
+			return hs
+		}
+	}
+	return append(hs, h)
+}
+
+// WrappedHandlers returns the Handlers of all of rh's routes, as well as its
+// default Handler.
+func (rh *RouterHandler[E]) WrappedHandlers() []Handler[E] {
+	rh.mtx.RLock()
+	defer rh.mtx.RUnlock()
+
+	var hs []Handler[E]
+	for _, r := range rh.routes {
+		hs = appendUniqueHandler(hs, r.Handler)
+	}
+	if rh.def != nil {
+		hs = appendUniqueHandler(hs, rh.def)
+	}
+	return hs
+}
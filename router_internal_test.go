@@ -0,0 +1,46 @@
+package jellog
+
+import "testing"
+
+func Test_matchComponent(t *testing.T) {
+	testCases := []struct {
+		name      string
+		pattern   string
+		component string
+		expect    bool
+	}{
+		{name: "exact", pattern: "db", component: "db", expect: true},
+		{name: "exact nested", pattern: "db.pool", component: "db.pool", expect: true},
+		{name: "different", pattern: "db", component: "http", expect: false},
+		{name: "sub-component", pattern: "db", component: "db.pool", expect: true},
+		{name: "deep sub-component", pattern: "db", component: "db.pool.conn", expect: true},
+		{name: "prefix without dot boundary", pattern: "db", component: "dbx", expect: false},
+		{name: "prefix without dot boundary in sub-component", pattern: "db", component: "dbx.pool", expect: false},
+		{name: "parent of pattern", pattern: "db.pool", component: "db", expect: false},
+		{name: "sibling of pattern", pattern: "db.pool", component: "db.query", expect: false},
+		{name: "dot star matches itself", pattern: "db.*", component: "db", expect: true},
+		{name: "dot star matches sub-component", pattern: "db.*", component: "db.pool", expect: true},
+		{name: "dot star matches deep sub-component", pattern: "db.*", component: "db.pool.conn", expect: true},
+		{name: "dot star at dot boundary", pattern: "db.*", component: "dbx", expect: false},
+		{name: "star matches everything", pattern: "*", component: "db.pool", expect: true},
+		{name: "star matches no component", pattern: "*", component: "", expect: true},
+		{name: "star within name", pattern: "d*", component: "dbx", expect: true},
+		{name: "wildcard has no sub-component matching", pattern: "d?", component: "db.pool", expect: false},
+		{name: "question mark", pattern: "d?", component: "db", expect: true},
+		{name: "character class", pattern: "[dh]b", component: "hb", expect: true},
+		{name: "leading star", pattern: "*.pool", component: "db.pool", expect: true},
+		{name: "leading star other leaf", pattern: "*.pool", component: "db.query", expect: false},
+		{name: "empty matches no component", pattern: "", component: "", expect: true},
+		{name: "empty does not match component", pattern: "", component: "db", expect: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := matchComponent(tc.pattern, tc.component)
+
+			if actual != tc.expect {
+				t.Errorf("expected matchComponent(%q, %q) to be %v, got %v", tc.pattern, tc.component, tc.expect, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package jellog_test
+
+import (
+	"testing"
+
+	"github.com/dekarrin/jellog"
+	"github.com/dekarrin/jellog/jellogtest"
+)
+
+func Test_RouterHandler_Output(t *testing.T) {
+	testCases := []struct {
+		name      string
+		mode      jellog.RouteMode
+		component string
+		expectDB  int
+		expectAll int
+		expectDef int
+	}{
+		{name: "first: specific route", mode: jellog.RouteFirst, component: "db.pool", expectDB: 1},
+		{name: "first: catch-all route", mode: jellog.RouteFirst, component: "http", expectAll: 1},
+		{name: "first: not a sub-component", mode: jellog.RouteFirst, component: "dbx", expectAll: 1},
+		{name: "first: no component goes to fallback", mode: jellog.RouteFirst, component: "", expectDef: 1},
+		{name: "all: every matching route", mode: jellog.RouteAll, component: "db", expectDB: 1, expectAll: 1},
+		{name: "all: no component goes to fallback", mode: jellog.RouteAll, component: "", expectDef: 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db := jellogtest.NewRecorder[string](nil)
+			all := jellogtest.NewRecorder[string](nil)
+			def := jellogtest.NewRecorder[string](nil)
+			rh := jellog.MustRouterHandler(&jellog.RouterOptions[string]{
+				Mode: tc.mode,
+				Routes: []jellog.Route[string]{
+					{Pattern: "db", Handler: db},
+					{Pattern: "?*", Handler: all},
+				},
+				Default: def,
+			})
+
+			if err := rh.Output(1, jellog.Event[string]{Level: jellog.LvInfo, Component: tc.component, Message: "m"}); err != nil {
+				t.Fatalf("Output: %v", err)
+			}
+
+			if db.Len() != tc.expectDB || all.Len() != tc.expectAll || def.Len() != tc.expectDef {
+				t.Errorf("expected %d/%d/%d events for db/all/fallback, got %d/%d/%d",
+					tc.expectDB, tc.expectAll, tc.expectDef, db.Len(), all.Len(), def.Len())
+			}
+		})
+	}
+}
+
+func Test_RouterHandler_routes(t *testing.T) {
+	db := jellogtest.NewRecorder[string](nil)
+	def := jellogtest.NewRecorder[string](nil)
+	rh := jellog.MustRouterHandler(&jellog.RouterOptions[string]{
+		HandlerOptions: jellog.HandlerOptions[string]{Component: "app"},
+	})
+
+	// the handler's component is chained on before matching
+	if err := rh.AddRoute("db.app", db); err != nil {
+		t.Fatalf("AddRoute: %v", err)
+	}
+	rh.Output(1, jellog.Event[string]{Level: jellog.LvInfo, Component: "db", Message: "m"})
+	jellogtest.AssertCount(t, db, jellogtest.InComponent[string]("db.app"), 1)
+
+	// without a fallback, unmatched events are dropped
+	rh.Output(1, jellog.Event[string]{Level: jellog.LvInfo, Component: "http", Message: "m"})
+	rh.SetDefault(def)
+	rh.Output(1, jellog.Event[string]{Level: jellog.LvInfo, Component: "http", Message: "m"})
+	if def.Len() != 1 {
+		t.Errorf("expected 1 event for fallback, got %d", def.Len())
+	}
+
+	if !rh.RemoveRoute("db.app") || rh.RemoveRoute("db.app") {
+		t.Errorf("expected route to be removed exactly once")
+	}
+	rh.Output(1, jellog.Event[string]{Level: jellog.LvInfo, Component: "db", Message: "m"})
+	if db.Len() != 1 || def.Len() != 2 {
+		t.Errorf("expected removed route's event to go to fallback")
+	}
+
+	if err := rh.AddRoute("[", db); err == nil {
+		t.Errorf("expected error for invalid pattern")
+	}
+	if err := rh.SetRoutes([]jellog.Route[string]{{Pattern: "ok", Handler: db}, {Pattern: "[", Handler: db}}); err == nil {
+		t.Errorf("expected error for invalid pattern")
+	}
+	if n := len(rh.Routes()); n != 0 {
+		t.Errorf("expected routes to be unchanged after error, got %d", n)
+	}
+	if _, err := jellog.NewRouterHandler(&jellog.RouterOptions[string]{Routes: []jellog.Route[string]{{Pattern: "["}}}); err == nil {
+		t.Errorf("expected error for invalid pattern")
+	}
+}
@@ -0,0 +1,353 @@
+package jellog
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimit is a token-bucket limit on how many events may be logged. Tokens
+// are added to the bucket at Rate per second, up to a maximum of Burst, and
+// each event that is let through uses one.
+type RateLimit struct {
+	// Rate is the number of events per second that are allowed on average.
+	Rate float64
+
+	// Burst is the maximum number of events that are allowed at once.
+	Burst int
+}
+
+// SamplingOptions is used to control the behavior of a SamplingHandler. It is
+// passed to NewSamplingHandler as an optional argument.
+type SamplingOptions[E any] struct {
+	// Interval is the length of each sampling period. Counts of events are
+	// reset at the start of each period, and a summary of the events
+	// suppressed during a period is emitted at its end. If not set, one second
+	// is used.
+	Interval time.Duration
+
+	// First is the number of events with the same key that are passed through
+	// in each period before sampling begins. If not set, 100 is used.
+	First int
+
+	// Thereafter is how often events with the same key are passed through
+	// once First have been passed through in the current period; every
+	// Thereafter-th event is passed through and the rest are suppressed. If
+	// not set, 100 is used. If negative, all events after the first First are
+	// suppressed.
+	Thereafter int
+
+	// Limits is an optional cap on how many events of each level are passed
+	// through, applied after sampling. Levels are matched by severity, and
+	// levels not included in the map are not capped.
+	Limits map[Level]RateLimit
+
+	// MaxKeys is the maximum number of distinct keys that are tracked in each
+	// period. Once it is reached, events with a key that isn't already tracked
+	// are all counted together as though they had the same key. If not set,
+	// 1000 is used.
+	MaxKeys int
+
+	// Key returns the key that an event is sampled by. Events with the same
+	// key are counted together. If not set, the key is made from the event's
+	// level, component, and message, with the message converted to a string
+	// using fmt.Sprint.
+	Key func(evt Event[E]) string
+
+	// Summarize creates the message of the event emitted at the end of a
+	// period for each key that had events suppressed. It is given the number
+	// of events that were suppressed and the most recent of them. If not set,
+	// the message is created by converting a string describing the suppressed
+	// events using the same default converter as a Logger. The event is marked
+	// Converted if the most recent suppressed event was, as the message
+	// usually includes it.
+	Summarize func(suppressed int, last Event[E]) E
+
+	// ErrorHandler is called with the error returned by the wrapped Handler
+	// when it fails to output a summary that is emitted because a period ended,
+	// as there is no caller to return the error to then. Errors from outputting
+	// events and summaries during a call to Output are returned from it as
+	// usual. If not set, these errors are reported to stderr at a limited rate,
+	// as they are for a Logger without an ErrorHandler. To apply a Logger's own
+	// error policy, give its HandleError method.
+	ErrorHandler func(err error)
+}
+
+// SamplingHandler is a Handler that wraps another Handler and limits how many
+// similar events are passed to it. Events are grouped by a key, which by
+// default is made from their level, component, and message. In each sampling
+// period, the first few events with a given key are passed through, and after
+// that only every so often; optionally, the total number of events of each
+// level can also be capped with a token bucket. At the end of each period, an
+// event summarizing how many were suppressed is passed through for each key
+// that had any suppressed.
+//
+// This protects destinations from being flooded by a hot loop that logs the
+// same thing over and over. The number of keys tracked at once is bounded, so
+// memory use does not grow without limit no matter how many distinct events are
+// logged.
+//
+// A SamplingHandler is safe to use from multiple goroutines. It should be
+// created with NewSamplingHandler.
+type SamplingHandler[E any] struct {
+	h    Handler[E]
+	opts SamplingOptions[E]
+
+	mtx      sync.Mutex
+	periodAt time.Time
+	counts   map[string]*sampleCount[E]
+	overflow sampleCount[E]
+	buckets  map[int]*tokenBucket
+	timer    *time.Timer
+	errs     errorState
+}
+
+type sampleCount[E any] struct {
+	seen       int
+	suppressed int
+	last       Event[E]
+}
+
+type tokenBucket struct {
+	limit  RateLimit
+	tokens float64
+	last   time.Time
+}
+
+// NewSamplingHandler creates a SamplingHandler that passes events through to h.
+//
+// To use the default set of SamplingOptions, pass nil for opts.
+func NewSamplingHandler[E any](h Handler[E], opts *SamplingOptions[E]) *SamplingHandler[E] {
+	var o SamplingOptions[E]
+	if opts != nil {
+		o = *opts
+	}
+	if o.Interval <= 0 {
+		o.Interval = time.Second
+	}
+	if o.First <= 0 {
+		o.First = 100
+	}
+	if o.Thereafter == 0 {
+		o.Thereafter = 100
+	}
+	if o.MaxKeys <= 0 {
+		o.MaxKeys = 1000
+	}
+	if o.Key == nil {
+		o.Key = defaultSampleKey[E]
+	}
+	if o.Summarize == nil {
+		conv := defaultConverter[E]()
+		o.Summarize = func(suppressed int, last Event[E]) E {
+			return conv(fmt.Sprintf("%d similar events were suppressed by sampling; last was: %v", suppressed, last.Message))
+		}
+	}
+
+	sh := &SamplingHandler[E]{
+		h:       h,
+		opts:    o,
+		counts:  make(map[string]*sampleCount[E]),
+		buckets: make(map[int]*tokenBucket),
+	}
+	for lv, limit := range o.Limits {
+		sh.buckets[lv.Severity] = &tokenBucket{limit: limit, tokens: float64(limit.Burst)}
+	}
+
+	return sh
+}
+
+// HandlerOptions returns the options of the Handler that sh wraps.
+func (sh *SamplingHandler[E]) HandlerOptions() HandlerOptions[E] {
+	return sh.h.HandlerOptions()
+}
+
+// InsertBreak inserts a break in the Handler that sh wraps. Breaks are never
+// sampled.
+func (sh *SamplingHandler[E]) InsertBreak() error {
+	return sh.h.InsertBreak()
+}
+
+// Output passes a log event through to the wrapped Handler unless it is
+// suppressed by sampling. A suppressed event results in a nil error.
+//
+// The calldepth argument is used for recovering the program counter. It should
+// be supplied with the number of levels into the jellog package that the caller
+// has reached, with the externally called function counting as 1.
+func (sh *SamplingHandler[E]) Output(calldepth int, evt Event[E]) error {
+	if evt.PC == 0 {
+		evt.PC = callerPC(calldepth)
+	}
+
+	now := time.Now()
+	key := sh.opts.Key(evt)
+
+	sh.mtx.Lock()
+	summaries := sh.rotate(now)
+
+	c, ok := sh.counts[key]
+	if !ok {
+		if len(sh.counts) < sh.opts.MaxKeys {
+			c = &sampleCount[E]{}
+			sh.counts[key] = c
+		} else {
+			c = &sh.overflow
+		}
+	}
+
+	c.seen++
+	pass := c.seen <= sh.opts.First
+	if !pass && sh.opts.Thereafter > 0 {
+		pass = (c.seen-sh.opts.First)%sh.opts.Thereafter == 0
+	}
+	if pass {
+		if b, ok := sh.buckets[evt.Level.Severity]; ok {
+			pass = b.take(now)
+		}
+	}
+	if !pass {
+		c.suppressed++
+		c.last = evt
+		if sh.timer == nil {
+			sh.timer = time.AfterFunc(sh.periodAt.Add(sh.opts.Interval).Sub(now), sh.timerFired)
+		}
+	}
+	sh.mtx.Unlock()
+
+	var fullErr error
+	for _, s := range summaries {
+		fullErr = joinHandlerErr(fullErr, sh.h.Output(calldepth+1, s))
+	}
+	if pass {
+		fullErr = joinHandlerErr(fullErr, sh.h.Output(calldepth+1, evt))
+	}
+	return fullErr
+}
+
+// timerFired is called at the end of a period in which events were suppressed
+// to emit their summaries even if nothing else is logged.
+func (sh *SamplingHandler[E]) timerFired() {
+	now := time.Now()
+
+	sh.mtx.Lock()
+	summaries := sh.rotate(now)
+	sh.timer = nil
+	if sh.hasSuppressed() {
+		// Output started a new period before we fired and has already
+		// suppressed events in it
+		sh.timer = time.AfterFunc(sh.periodAt.Add(sh.opts.Interval).Sub(now), sh.timerFired)
+	}
+	sh.mtx.Unlock()
+
+	var fullErr error
+	for _, s := range summaries {
+		fullErr = joinHandlerErr(fullErr, sh.h.Output(1, s))
+	}
+	sh.errs.handle(sh.opts.ErrorHandler, fullErr)
+}
+
+// rotate starts a new period if the current one has ended, and returns the
+// summary events for the period that ended. sh.mtx must be held.
+func (sh *SamplingHandler[E]) rotate(now time.Time) []Event[E] {
+	if now.Sub(sh.periodAt) < sh.opts.Interval {
+		return nil
+	}
+
+	var summaries []Event[E]
+	for _, c := range sh.counts {
+		if c.suppressed > 0 {
+			summaries = append(summaries, sh.summary(c))
+		}
+	}
+	if sh.overflow.suppressed > 0 {
+		summaries = append(summaries, sh.summary(&sh.overflow))
+	}
+
+	sh.periodAt = now
+	sh.counts = make(map[string]*sampleCount[E])
+	sh.overflow = sampleCount[E]{}
+
+	return summaries
+}
+
+func (sh *SamplingHandler[E]) hasSuppressed() bool {
+	if sh.overflow.suppressed > 0 {
+		return true
+	}
+	for _, c := range sh.counts {
+		if c.suppressed > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (sh *SamplingHandler[E]) summary(c *sampleCount[E]) Event[E] {
+	evt := Event[E]{
+		Time:      time.Now(),
+		Level:     c.last.Level,
+		Component: c.last.Component,
+		PC:        c.last.PC,
+		Attrs:     []Attr{{Key: "suppressed", Value: c.suppressed}},
+		Converted: c.last.Converted,
+		Message:   sh.opts.Summarize(c.suppressed, c.last),
+		ctx:       c.last.ctx,
+	}
+	return evt
+}
+
+func (b *tokenBucket) take(now time.Time) bool {
+	if !b.last.IsZero() {
+		b.tokens += now.Sub(b.last).Seconds() * b.limit.Rate
+		if b.tokens > float64(b.limit.Burst) {
+			b.tokens = float64(b.limit.Burst)
+		}
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+func defaultSampleKey[E any](evt Event[E]) string {
+	return strconv.Itoa(evt.Level.Severity) + "\x00" + evt.Component + "\x00" + fmt.Sprint(evt.Message)
+}
+
+// WrappedHandlers returns the Handler that sh wraps.
+func (sh *SamplingHandler[E]) WrappedHandlers() []Handler[E] {
+	return []Handler[E]{sh.h}
+}
+
+// Flush immediately passes through the events summarizing what has been
+// suppressed in the current period, without waiting for it to end. It does not
+// flush the wrapped Handler; use FlushHandlers or Logger.Flush for that.
+func (sh *SamplingHandler[E]) Flush(ctx context.Context) error {
+	sh.mtx.Lock()
+	var summaries []Event[E]
+	for _, c := range sh.counts {
+		if c.suppressed > 0 {
+			summaries = append(summaries, sh.summary(c))
+			c.suppressed = 0
+		}
+	}
+	if sh.overflow.suppressed > 0 {
+		summaries = append(summaries, sh.summary(&sh.overflow))
+		sh.overflow.suppressed = 0
+	}
+	if sh.timer != nil {
+		sh.timer.Stop()
+		sh.timer = nil
+	}
+	sh.mtx.Unlock()
+
+	var fullErr error
+	for _, s := range summaries {
+		fullErr = joinHandlerErr(fullErr, sh.h.Output(1, s))
+	}
+	return fullErr
+}
@@ -0,0 +1,258 @@
+package jellog_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dekarrin/jellog"
+	"github.com/dekarrin/jellog/jellogtest"
+)
+
+func Test_SamplingHandler_Output(t *testing.T) {
+	testCases := []struct {
+		name       string
+		opts       jellog.SamplingOptions[string]
+		msgs       []string
+		expectPass int
+	}{
+		{
+			name:       "under First passes everything",
+			opts:       jellog.SamplingOptions[string]{First: 5},
+			msgs:       repeat("same", 5),
+			expectPass: 5,
+		},
+		{
+			name:       "every Thereafter-th after First",
+			opts:       jellog.SamplingOptions[string]{First: 3, Thereafter: 5},
+			msgs:       repeat("same", 20),
+			expectPass: 6,
+		},
+		{
+			name:       "negative Thereafter suppresses the rest",
+			opts:       jellog.SamplingOptions[string]{First: 3, Thereafter: -1},
+			msgs:       repeat("same", 20),
+			expectPass: 3,
+		},
+		{
+			name:       "keys are counted separately",
+			opts:       jellog.SamplingOptions[string]{First: 2, Thereafter: -1},
+			msgs:       append(repeat("a", 5), repeat("b", 5)...),
+			expectPass: 4,
+		},
+		{
+			name:       "keys past MaxKeys are counted together",
+			opts:       jellog.SamplingOptions[string]{First: 1, Thereafter: -1, MaxKeys: 2},
+			msgs:       []string{"a", "b", "c", "d", "e"},
+			expectPass: 3,
+		},
+		{
+			name: "custom Key",
+			opts: jellog.SamplingOptions[string]{First: 1, Thereafter: -1, Key: func(evt jellog.Event[string]) string {
+				return strings.Fields(evt.Message)[0]
+			}},
+			msgs:       []string{"user 1 logged in", "user 2 logged in", "job 1 started"},
+			expectPass: 2,
+		},
+		{
+			name: "level limit",
+			opts: jellog.SamplingOptions[string]{Limits: map[jellog.Level]jellog.RateLimit{
+				jellog.LvInfo: {Rate: 0.001, Burst: 3},
+			}},
+			msgs:       []string{"a", "b", "c", "d", "e"},
+			expectPass: 3,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := jellogtest.NewRecorder[string](nil)
+			opts := tc.opts
+			opts.Interval = time.Hour
+			sh := jellog.NewSamplingHandler[string](rec, &opts)
+
+			for _, msg := range tc.msgs {
+				if err := sh.Output(1, jellog.Event[string]{Level: jellog.LvInfo, Message: msg}); err != nil {
+					t.Fatalf("Output: %v", err)
+				}
+			}
+			if rec.Len() != tc.expectPass {
+				t.Fatalf("expected %d events to pass, got %d", tc.expectPass, rec.Len())
+			}
+
+			// whatever was not passed is reported by the summaries
+			if err := sh.Flush(context.Background()); err != nil {
+				t.Fatalf("Flush: %v", err)
+			}
+			summarized := 0
+			for _, evt := range rec.Filter(jellogtest.HasAttr[string]("suppressed")) {
+				summarized += attrValue(evt, "suppressed").(int)
+			}
+			if expect := len(tc.msgs) - tc.expectPass; summarized != expect {
+				t.Errorf("expected summaries to report %d suppressed events, got %d", expect, summarized)
+			}
+		})
+	}
+}
+
+func Test_SamplingHandler_summaryConverted(t *testing.T) {
+	testCases := []struct {
+		name      string
+		converted bool
+	}{
+		{name: "converted", converted: true},
+		{name: "not converted", converted: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := jellogtest.NewRecorder[string](nil)
+			sh := jellog.NewSamplingHandler[string](rec, &jellog.SamplingOptions[string]{
+				Interval:   time.Hour,
+				First:      1,
+				Thereafter: -1,
+			})
+
+			for i := 0; i < 3; i++ {
+				sh.Output(1, jellog.Event[string]{Level: jellog.LvInfo, Message: "a\nb", Converted: tc.converted})
+			}
+			if err := sh.Flush(context.Background()); err != nil {
+				t.Fatalf("Flush: %v", err)
+			}
+
+			evts := rec.Filter(jellogtest.HasAttr[string]("suppressed"))
+			if len(evts) != 1 {
+				t.Fatalf("expected 1 summary, got %d", len(evts))
+			}
+			if evts[0].Converted != tc.converted {
+				t.Errorf("expected summary Converted to be %v, got %v", tc.converted, evts[0].Converted)
+			}
+		})
+	}
+}
+
+func Test_SamplingHandler_timer(t *testing.T) {
+	rec := jellogtest.NewRecorder[string](nil)
+	sh := jellog.NewSamplingHandler[string](rec, &jellog.SamplingOptions[string]{
+		Interval:   20 * time.Millisecond,
+		First:      1,
+		Thereafter: -1,
+	})
+
+	for i := 0; i < 5; i++ {
+		sh.Output(1, jellog.Event[string]{Level: jellog.LvWarn, Component: "db", Message: "slow query"})
+	}
+
+	// the summary is emitted at the end of the period even though nothing
+	// else is logged
+	summaries := jellogtest.HasAttr[string]("suppressed")
+	waitFor(t, "summary", func() bool { return rec.Count(summaries) > 0 })
+
+	evts := rec.Filter(summaries)
+	if len(evts) != 1 {
+		t.Fatalf("expected 1 summary, got %d", len(evts))
+	}
+	if evts[0].Level.Severity != jellog.LvWarn.Severity || evts[0].Component != "db" {
+		t.Errorf("expected summary to have level and component of the suppressed events, got %s (%s)", evts[0].Level, evts[0].Component)
+	}
+	jellogtest.AssertHasEvent(t, rec, jellog.LvWarn, "4 similar events were suppressed by sampling; last was: slow query")
+
+	// nothing was suppressed in the next period, so nothing more is emitted
+	time.Sleep(60 * time.Millisecond)
+	jellogtest.AssertCount(t, rec, summaries, 1)
+}
+
+func Test_SamplingHandler_summaryErrors(t *testing.T) {
+	errSummary := errors.New("cannot write summary")
+
+	testCases := []struct {
+		name           string
+		interval       time.Duration
+		emit           func(sh *jellog.SamplingHandler[string]) error
+		expectReturned bool
+	}{
+		{
+			name:     "timer reports to ErrorHandler",
+			interval: 20 * time.Millisecond,
+			emit:     func(sh *jellog.SamplingHandler[string]) error { return nil },
+		},
+		{
+			name:           "Flush returns error",
+			interval:       time.Hour,
+			emit:           func(sh *jellog.SamplingHandler[string]) error { return sh.Flush(context.Background()) },
+			expectReturned: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var mtx sync.Mutex
+			var reported []error
+
+			fh := &failingHandler{
+				Recorder: jellogtest.NewRecorder[string](nil),
+				fail:     jellogtest.HasAttr[string]("suppressed"),
+				err:      errSummary,
+			}
+			sh := jellog.NewSamplingHandler[string](fh, &jellog.SamplingOptions[string]{
+				Interval:   tc.interval,
+				First:      1,
+				Thereafter: -1,
+				ErrorHandler: func(err error) {
+					mtx.Lock()
+					defer mtx.Unlock()
+					reported = append(reported, err)
+				},
+			})
+
+			sh.Output(1, jellog.Event[string]{Level: jellog.LvInfo, Message: "again"})
+			sh.Output(1, jellog.Event[string]{Level: jellog.LvInfo, Message: "again"})
+
+			// errors are returned to a caller if there is one, and only given
+			// to ErrorHandler otherwise
+			err := tc.emit(sh)
+			if tc.expectReturned {
+				if !errors.Is(err, errSummary) {
+					t.Errorf("expected error to wrap %v, got %v", errSummary, err)
+				}
+				mtx.Lock()
+				defer mtx.Unlock()
+				if len(reported) > 0 {
+					t.Errorf("expected nothing to be reported to ErrorHandler, got %v", reported)
+				}
+				return
+			}
+			waitFor(t, "error to be reported", func() bool {
+				mtx.Lock()
+				defer mtx.Unlock()
+				return len(reported) > 0
+			})
+			mtx.Lock()
+			defer mtx.Unlock()
+			if !errors.Is(reported[0], errSummary) {
+				t.Errorf("expected reported error to wrap %v, got %v", errSummary, reported[0])
+			}
+		})
+	}
+}
+
+func repeat(msg string, n int) []string {
+	msgs := make([]string, n)
+	for i := range msgs {
+		msgs[i] = msg
+	}
+	return msgs
+}
+
+func attrValue(evt jellog.Event[string], key string) any {
+	for _, a := range evt.Attrs {
+		if a.Key == key {
+			return a.Value
+		}
+	}
+	panic(fmt.Sprintf("event has no attr %q", key))
+}
@@ -0,0 +1,89 @@
+package jellog
+
+import (
+	"runtime"
+	"strconv"
+)
+
+// maxStackDepth is the most frames that are captured for a stack trace.
+// Anything deeper than this is cut off.
+const maxStackDepth = 64
+
+// Frame is a single function call in a stack trace attached to an Event. It
+// has JSON tags so that structured Formatters can emit a stack trace directly
+// as an array of objects.
+type Frame struct {
+	// Function is the fully package-qualified name of the function, such as
+	// "main.run" or "github.com/dekarrin/jellog.(*Logger[...]).Error".
+	Function string `json:"function"`
+
+	// File is the full path to the source file that the call is in.
+	File string `json:"file"`
+
+	// Line is the line number within File of the call.
+	Line int `json:"line"`
+}
+
+// String returns the frame in the form "function (file:line)".
+func (f Frame) String() string {
+	return f.Function + " (" + f.File + ":" + strconv.Itoa(f.Line) + ")"
+}
+
+// captureStack returns the stack of the current goroutine starting with the
+// function calldepth levels above the function calling captureStack, with the
+// caller of captureStack counting as 0. This uses the same counting as
+// callerPC, so the first frame is the one callerPC would return.
+func captureStack(calldepth int) []Frame {
+	return stackFrames(callerPCs(calldepth + 1))
+}
+
+// callerPCs returns the program counters of the stack that captureStack would
+// return, without expanding them into Frames. It uses the same counting as
+// captureStack.
+func callerPCs(calldepth int) []uintptr {
+	pcs := make([]uintptr, maxStackDepth)
+	// skip runtime.Callers and callerPCs itself
+	n := runtime.Callers(calldepth+2, pcs)
+	return pcs[:n]
+}
+
+// stackFrames expands the program counters returned by runtime.Callers into
+// Frames. The runtime's own goroutine entry point is left out, as it is at the
+// bottom of every stack and tells nothing about what the program was doing.
+func stackFrames(pcs []uintptr) []Frame {
+	if len(pcs) < 1 {
+		return nil
+	}
+
+	var stack []Frame
+	frames := runtime.CallersFrames(pcs)
+	for {
+		f, more := frames.Next()
+		if f.Function != "runtime.goexit" {
+			stack = append(stack, Frame{Function: f.Function, File: f.File, Line: f.Line})
+		}
+		if !more {
+			break
+		}
+	}
+	return stack
+}
+
+// appendStack appends stack to buf as indented lines in the same layout that
+// Go itself uses for goroutine traces, with each function on one line and its
+// file and line number on the next:
+//
+//	main.run
+//		/home/user/src/main.go:42
+func appendStack(buf []byte, stack []Frame) []byte {
+	for _, f := range stack {
+		buf = append(buf, '\t')
+		buf = append(buf, f.Function...)
+		buf = append(buf, "\n\t\t"...)
+		buf = append(buf, f.File...)
+		buf = append(buf, ':')
+		buf = strconv.AppendInt(buf, int64(f.Line), 10)
+		buf = append(buf, '\n')
+	}
+	return buf
+}
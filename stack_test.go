@@ -0,0 +1,131 @@
+package jellog_test
+
+import (
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/dekarrin/jellog"
+	"github.com/dekarrin/jellog/jellogtest"
+)
+
+func Test_Logger_Output_stackTrace(t *testing.T) {
+	testCases := []struct {
+		name        string
+		opts        jellog.Options[string]
+		lv          jellog.Level
+		expectStack bool
+	}{
+		{name: "not set", opts: jellog.Defaults[string](), lv: jellog.LvFatal, expectStack: false},
+		{name: "below level", opts: jellog.Defaults[string]().WithStackTraceLevel(jellog.LvError), lv: jellog.LvWarn, expectStack: false},
+		{name: "at level", opts: jellog.Defaults[string]().WithStackTraceLevel(jellog.LvError), lv: jellog.LvError, expectStack: true},
+		{name: "above level", opts: jellog.Defaults[string]().WithStackTraceLevel(jellog.LvError), lv: jellog.LvFatal, expectStack: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := jellogtest.NewRecorder[string](nil)
+			lg := jellog.New(tc.opts)
+			lg.AddHandler(jellog.LvTrace, rec)
+
+			line := logFromHelper(lg, tc.lv)
+
+			evts := rec.Events()
+			if len(evts) != 1 {
+				t.Fatalf("expected 1 event, got %d", len(evts))
+			}
+			stack := evts[0].Stack
+			if !tc.expectStack {
+				if stack != nil {
+					t.Errorf("expected no stack, got %v", stack)
+				}
+				return
+			}
+			if len(stack) < 2 {
+				t.Fatalf("expected stack of at least 2 frames, got %v", stack)
+			}
+
+			// the trace starts at the call site and continues up through its
+			// callers
+			if !strings.HasSuffix(stack[0].Function, ".logFromHelper") || filepath.Base(stack[0].File) != "stack_test.go" || stack[0].Line != line {
+				t.Errorf("expected first frame to be logFromHelper at stack_test.go:%d, got %v", line, stack[0])
+			}
+			if !strings.Contains(stack[1].Function, ".Test_Logger_Output_stackTrace") {
+				t.Errorf("expected second frame to be the test function, got %v", stack[1])
+			}
+			for _, f := range stack {
+				if strings.HasPrefix(f.Function, "github.com/dekarrin/jellog.") {
+					t.Errorf("expected no frames inside jellog, got %v", f)
+				}
+				if f.Function == "runtime.goexit" {
+					t.Errorf("expected goroutine entry point to be left out, got %v", f)
+				}
+			}
+		})
+	}
+}
+
+// logFromHelper logs at lv with lg and returns the line number it did so on.
+func logFromHelper(lg jellog.Logger[string], lv jellog.Level) int {
+	lg.Log(lv, "a")
+	_, _, line, _ := runtime.Caller(0)
+	return line - 1
+}
+
+func Test_Logger_Output_stackTraceKept(t *testing.T) {
+	given := []jellog.Frame{{Function: "main.run", File: "/src/main.go", Line: 42}}
+	rec := jellogtest.NewRecorder[string](nil)
+	lg := jellog.New(jellog.Defaults[string]().WithStackTraceLevel(jellog.LvError))
+	lg.AddHandler(jellog.LvTrace, rec)
+
+	evt := lg.CreateEvent(jellog.LvError, "a")
+	evt.Stack = given
+	if err := lg.Output(1, evt); err != nil {
+		t.Fatalf("Output: %v", err)
+	}
+
+	if actual := rec.Events()[0].Stack; len(actual) != 1 || actual[0] != given[0] {
+		t.Errorf("expected stack %v to be kept, got %v", given, actual)
+	}
+}
+
+func Test_Logger_Copy_stackTraceLevel(t *testing.T) {
+	rec := jellogtest.NewRecorder[string](nil)
+	base := jellog.New(jellog.Defaults[string]().WithStackTraceLevel(jellog.LvError))
+	base.AddHandler(jellog.LvTrace, rec)
+
+	base.Copy(jellog.Options[string]{}).Error("inherited")
+	base.Copy(jellog.Options[string]{}.WithStackTraceLevel(jellog.LvFatal)).Error("overridden")
+
+	evts := rec.Events()
+	if len(evts) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(evts))
+	}
+	if evts[0].Stack == nil {
+		t.Errorf("expected copy to inherit StackTraceLevel")
+	}
+	if evts[1].Stack != nil {
+		t.Errorf("expected copy to use its own StackTraceLevel, got stack %v", evts[1].Stack)
+	}
+}
+
+func Test_LineFormat_Format_stack(t *testing.T) {
+	evt := jellog.Event[string]{
+		Level:   jellog.LvError,
+		Message: "a",
+		Stack: []jellog.Frame{
+			{Function: "main.load", File: "/src/load.go", Line: 7},
+			{Function: "main.run", File: "/src/main.go", Line: 42},
+		},
+	}
+	expect := "ERROR a\n" +
+		"\tmain.load\n\t\t/src/load.go:7\n" +
+		"\tmain.run\n\t\t/src/main.go:42\n"
+
+	actual := string(jellog.LineFormat{OmitTime: true}.Format(evt))
+
+	if actual != expect {
+		t.Errorf("expected %q, got %q", expect, actual)
+	}
+}
@@ -12,6 +12,10 @@ var mtxStderr sync.Mutex
 // StderrHandler is ready to use with default options; to set the options, use
 // NewStderrHandler.
 //
+// If the configured Formatter is a TerminalFormatter, it is told whether stderr
+// is a terminal before being used. With the default Formatter, this results in
+// colorized output when stderr is a terminal.
+//
 // Writes to Stderr using StderrHandler are serialized, even across multiple
 // StderrHandler instances. It is safe to use any number of StderrHandlers
 // simultaneously from any number of goroutines.
@@ -38,12 +42,7 @@ func NewStderrHandler(opts *HandlerOptions[string]) *StderrHandler {
 // used depends on the Formatter seh is configured with; for the default
 // Formatter, it is the newline '\n'.
 func (seh *StderrHandler) InsertBreak() error {
-	var buf []byte
-	if seh.opts.Formatter != nil {
-		buf = seh.opts.Formatter.Break()
-	} else {
-		buf = defFormatter.Break()
-	}
+	buf := seh.formatter().Break()
 
 	mtxStderr.Lock()
 	defer mtxStderr.Unlock()
@@ -74,12 +73,7 @@ func (seh *StderrHandler) Output(calldepth int, evt Event[string]) error {
 		evt.Component += seh.opts.Component
 	}
 
-	var buf []byte
-	if seh.opts.Formatter != nil {
-		buf = seh.opts.Formatter.Format(evt)
-	} else {
-		buf = defFormatter.Format(evt)
-	}
+	buf := seh.formatter().Format(evt)
 
 	mtxStderr.Lock()
 	defer mtxStderr.Unlock()
@@ -87,3 +81,17 @@ func (seh *StderrHandler) Output(calldepth int, evt Event[string]) error {
 	_, err := os.Stderr.Write(buf)
 	return err
 }
+
+// formatter returns the Formatter that seh should use, adjusted for whether
+// stderr is a terminal.
+func (seh *StderrHandler) formatter() Formatter[string] {
+	var f Formatter[string] = defFormatter
+	if seh.opts.Formatter != nil {
+		f = seh.opts.Formatter
+	}
+
+	if tf, ok := f.(TerminalFormatter[string]); ok {
+		f = tf.ForTerminal(stderrIsTerminal())
+	}
+	return f
+}
@@ -0,0 +1,85 @@
+package jellog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// termFormat is a TerminalFormatter that records what it was told by
+// ForTerminal.
+type termFormat struct {
+	LineFormat
+	asked    bool
+	terminal bool
+}
+
+func (tf termFormat) ForTerminal(isTerminal bool) Formatter[string] {
+	tf.asked = true
+	tf.terminal = isTerminal
+	return tf
+}
+
+func Test_StderrHandler_formatter(t *testing.T) {
+	seh := NewStderrHandler(&HandlerOptions[string]{Formatter: termFormat{}})
+
+	f, ok := seh.formatter().(termFormat)
+	if !ok {
+		t.Fatalf("expected a termFormat, got %T", seh.formatter())
+	}
+	if !f.asked {
+		t.Errorf("expected ForTerminal to be called")
+	}
+	if expect := isTerminal(os.Stderr); f.terminal != expect {
+		t.Errorf("expected ForTerminal to be told terminal is %v, got %v", expect, f.terminal)
+	}
+}
+
+func Test_StderrHandler_formatter_env(t *testing.T) {
+	testCases := []struct {
+		name       string
+		forceColor string
+		noColor    string
+		expect     ColorMode
+	}{
+		{name: "FORCE_COLOR", forceColor: "1", expect: ColorAlways},
+		{name: "NO_COLOR", noColor: "1", expect: ColorNever},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("FORCE_COLOR", tc.forceColor)
+			t.Setenv("NO_COLOR", tc.noColor)
+			var seh StderrHandler
+
+			lf, ok := seh.formatter().(LineFormat)
+			if !ok {
+				t.Fatalf("expected a LineFormat, got %T", seh.formatter())
+			}
+			if lf.Color != tc.expect {
+				t.Errorf("expected color mode %v, got %v", tc.expect, lf.Color)
+			}
+		})
+	}
+}
+
+func Test_isTerminal(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "log.txt"))
+	if err != nil {
+		t.Fatalf("create file: %v", err)
+	}
+	defer f.Close()
+	if isTerminal(f) {
+		t.Errorf("expected regular file not to be a terminal")
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("create pipe: %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+	if isTerminal(w) {
+		t.Errorf("expected pipe not to be a terminal")
+	}
+}